@@ -0,0 +1,17 @@
+//go:build !headless
+
+package main
+
+import (
+	"github.com/dev/falcon-agent/internal/service"
+	"github.com/dev/falcon-agent/pkg/ui"
+)
+
+// guiAvailable indica se o binário foi compilado com a interface gráfica
+const guiAvailable = true
+
+// runGUI abre a janela principal e bloqueia até a aplicação ser encerrada
+func runGUI(agent *service.Agent) {
+	app := ui.New(agent)
+	app.Run()
+}
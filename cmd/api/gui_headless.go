@@ -0,0 +1,12 @@
+//go:build headless
+
+package main
+
+import "github.com/dev/falcon-agent/internal/service"
+
+// guiAvailable indica se o binário foi compilado com a interface gráfica.
+// Com a tag headless o Fyne não é ligado ao binário e o agente sempre
+// roda como daemon.
+const guiAvailable = false
+
+func runGUI(agent *service.Agent) {}
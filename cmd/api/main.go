@@ -1,32 +1,88 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/dev/falcon-agent/internal/config"
 	"github.com/dev/falcon-agent/internal/service"
+	"github.com/dev/falcon-agent/internal/systemd"
 	"github.com/dev/falcon-agent/pkg/logger"
-	"github.com/dev/falcon-agent/pkg/ui"
 )
 
 func main() {
-	// Inicializa a configuração
-	cfg := config.New()
+	// Inicializa a configuração: padrões, arquivo, ambiente e flags
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err == nil {
+		err = service.CheckCollectors(cfg)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuração inválida:\n%v\n", err)
+		os.Exit(2)
+	}
 
 	// Inicializa o logger
-	log, err := logger.New(cfg.LogPath)
+	log, err := logger.New(logger.Options{
+		Dir:      cfg.LogPath,
+		Level:    cfg.LogLevel,
+		Format:   cfg.LogFormat,
+		Rotation: cfg.LogRotation,
+		Sinks:    cfg.LogSinks,
+		Syslog:   cfg.LogSyslog,
+		Stdout:   true,
+	})
 	if err != nil {
-		panic(err)
+		fmt.Fprintf(os.Stderr, "Erro ao iniciar o log: %v\n", err)
+		os.Exit(1)
 	}
+	defer log.Close()
+	logger.SetDefault(log)
 
 	log.Info("Iniciando Falcon Agent...")
+	if cfg.ConfigFile != "" {
+		log.Info("Configuração carregada de %s", cfg.ConfigFile)
+	}
 
-	// Coleta informações da máquina
-	machineInfo, err := service.CollectMachineInfo()
-	if err != nil {
-		log.Error("Erro ao coletar informações da máquina: %v", err)
+	// Inicia o agente (coleta de informações e API HTTP)
+	agent := service.New(cfg, log)
+	if err := agent.Start(); err != nil {
+		log.Error("Erro ao iniciar o agente: %v", err)
 		panic(err)
 	}
 
+	if cfg.Headless || !guiAvailable {
+		runHeadless(agent, log)
+		return
+	}
+
 	// Inicia a interface gráfica
-	app := ui.New(machineInfo)
-	app.Run()
+	defer agent.Stop()
+	runGUI(agent)
+}
+
+// runHeadless mantém o agente em execução até receber SIGTERM ou SIGINT
+func runHeadless(agent *service.Agent, log logger.Logger) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+
+	if _, err := systemd.Notify(systemd.Ready); err != nil {
+		log.Error("Erro ao notificar o systemd: %v", err)
+	}
+	log.Info("Falcon Agent em execução no modo headless")
+
+	sig := <-signals
+	log.Info("Sinal recebido: %v", sig)
+
+	systemd.Notify(systemd.Stopping)
+	if err := agent.Stop(); err != nil {
+		log.Error("Erro ao parar o agente: %v", err)
+		os.Exit(1)
+	}
 }
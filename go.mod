@@ -7,10 +7,13 @@ toolchain go1.24.1
 require (
 	fyne.io/fyne/v2 v2.4.4
 	fyne.io/systray v1.10.1-0.20231115130155-104f5ef7839e
+	github.com/fsnotify/fsnotify v1.7.0
 	github.com/google/gousb v1.1.2
 	github.com/jaypipes/ghw v0.16.0
 	github.com/shirou/gopsutil/v3 v3.24.5
+	golang.org/x/sys v0.30.0
 	gonum.org/v1/plot v0.16.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -23,7 +26,6 @@ require (
 	github.com/campoy/embedmd v1.0.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/fredbi/uri v1.1.0 // indirect
-	github.com/fsnotify/fsnotify v1.7.0 // indirect
 	github.com/fyne-io/gl-js v0.0.0-20220119005834-d2da28d9ccfe // indirect
 	github.com/fyne-io/glfw-js v0.0.0-20220120001248-ee7290d23504 // indirect
 	github.com/fyne-io/image v0.0.0-20220602074514-4956b0afb3d2 // indirect
@@ -54,9 +56,7 @@ require (
 	golang.org/x/image v0.25.0 // indirect
 	golang.org/x/mobile v0.0.0-20230531173138-3c911d8e3eda // indirect
 	golang.org/x/net v0.17.0 // indirect
-	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.23.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 	honnef.co/go/js/dom v0.0.0-20210725211120-f030747120f2 // indirect
 	howett.net/plist v1.0.0 // indirect
 )
@@ -0,0 +1,272 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dev/falcon-agent/internal/metrics"
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+const (
+	prometheusContentType  = "text/plain; version=0.0.4; charset=utf-8"
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// systemGauges associa as séries de SystemMetrics aos nomes exportados
+var systemGauges = []struct {
+	metric string
+	name   string
+	help   string
+}{
+	{"cpu_usage", "falcon_cpu_usage_percent", "Uso total de CPU em porcentagem."},
+	{"memory_usage", "falcon_memory_usage_percent", "Uso de memória em porcentagem."},
+	{"swap_usage", "falcon_swap_usage_percent", "Uso de swap em porcentagem."},
+	{"load1", "falcon_load1", "Carga média do sistema em 1 minuto."},
+	{"load5", "falcon_load5", "Carga média do sistema em 5 minutos."},
+	{"load15", "falcon_load15", "Carga média do sistema em 15 minutos."},
+	{"disk_read_bytes", "falcon_disk_read_bytes_per_second", "Taxa de leitura em disco em bytes por segundo."},
+	{"disk_write_bytes", "falcon_disk_write_bytes_per_second", "Taxa de escrita em disco em bytes por segundo."},
+	{"net_recv_bytes", "falcon_network_receive_bytes_per_second", "Tráfego de rede recebido em bytes por segundo."},
+	{"net_sent_bytes", "falcon_network_transmit_bytes_per_second", "Tráfego de rede enviado em bytes por segundo."},
+}
+
+// label é um par nome/valor de rótulo de uma amostra
+type label struct {
+	name, value string
+}
+
+// exposition monta a saída no formato de texto do Prometheus. O formato
+// OpenMetrics é o mesmo para gauges, acrescido do marcador "# EOF".
+type exposition struct {
+	buf bytes.Buffer
+	// header é o cabeçalho da família atual, escrito só junto com a
+	// primeira amostra para que não apareçam famílias vazias
+	header string
+}
+
+// gauge inicia uma família de métricas do tipo gauge
+func (e *exposition) gauge(name, help string) {
+	e.header = fmt.Sprintf("# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
+
+// sample escreve uma amostra da família atual
+func (e *exposition) sample(name string, value float64, labels ...label) {
+	e.buf.WriteString(e.header)
+	e.header = ""
+	e.buf.WriteString(name)
+	if len(labels) > 0 {
+		e.buf.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			fmt.Fprintf(&e.buf, "%s=\"%s\"", l.name, escapeLabel(l.value))
+		}
+		e.buf.WriteByte('}')
+	}
+	e.buf.WriteByte(' ')
+	e.buf.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	e.buf.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+// handlePrometheus expõe as métricas amostradas e gauges derivados do
+// inventário para coleta pelo Prometheus
+func (s *Server) handlePrometheus(w http.ResponseWriter, r *http.Request) {
+	e := &exposition{}
+	writeSystemMetrics(e, s.source.Metrics())
+	if processes := s.source.Processes(); len(processes) > 0 {
+		e.gauge("falcon_processes", "Número de processos em execução.")
+		e.sample("falcon_processes", float64(len(processes)))
+	}
+	if info := s.source.MachineInfo(); info != nil {
+		writeInventory(e, info)
+	}
+
+	contentType := prometheusContentType
+	if strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text") {
+		contentType = openMetricsContentType
+		e.buf.WriteString("# EOF\n")
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(e.buf.Bytes())
+}
+
+// writeSystemMetrics exporta o valor mais recente de cada série
+func writeSystemMetrics(e *exposition, m *metrics.SystemMetrics) {
+	histories := m.Histories()
+	for _, g := range systemGauges {
+		if point, ok := histories[g.metric].Last(); ok {
+			e.gauge(g.name, g.help)
+			e.sample(g.name, point.Value)
+		}
+	}
+
+	e.gauge("falcon_cpu_core_usage_percent", "Uso de CPU por núcleo lógico em porcentagem.")
+	for i, core := range m.CPUCoreUsage {
+		if point, ok := core.Last(); ok {
+			e.sample("falcon_cpu_core_usage_percent", point.Value, label{"core", strconv.Itoa(i)})
+		}
+	}
+}
+
+// writeInventory exporta o inventário como gauges de informação (valor 1
+// com os dados nos rótulos) e gauges de tamanho e quantidade
+func writeInventory(e *exposition, info *model.MachineInfo) {
+	e.gauge("falcon_machine_info", "Identificação da máquina.")
+	e.sample("falcon_machine_info", 1,
+		label{"hostname", info.Hostname},
+		label{"os", info.OS},
+		label{"serial_number", info.SerialNumber},
+		label{"motherboard_serial", info.MotherboardSN},
+	)
+
+	osInfo := info.OSDetails
+	e.gauge("falcon_os_info", "Distribuição, kernel e ambiente do sistema operacional.")
+	e.sample("falcon_os_info", 1,
+		label{"id", osInfo.ID},
+		label{"version_id", osInfo.VersionID},
+		label{"kernel", osInfo.Kernel},
+		label{"arch", osInfo.Arch},
+		label{"virtualization", osInfo.Virtualization},
+		label{"secure_boot", osInfo.SecureBoot},
+	)
+	if !osInfo.BootTime.IsZero() {
+		e.gauge("falcon_boot_time_seconds", "Momento da inicialização do sistema, em segundos desde a época Unix.")
+		e.sample("falcon_boot_time_seconds", float64(osInfo.BootTime.Unix()))
+	}
+
+	e.gauge("falcon_bios_info", "Fabricante e versão da BIOS.")
+	e.sample("falcon_bios_info", 1,
+		label{"vendor", info.BIOS.Vendor},
+		label{"version", info.BIOS.Version},
+		label{"release_date", info.BIOS.ReleaseDate},
+	)
+
+	e.gauge("falcon_processor_info", "Modelo do processador.")
+	e.sample("falcon_processor_info", 1, label{"model", info.Processor.Model})
+	e.gauge("falcon_processor_cores", "Número de núcleos físicos do processador.")
+	e.sample("falcon_processor_cores", float64(info.Processor.Cores))
+	e.gauge("falcon_processor_threads", "Número de threads do processador.")
+	e.sample("falcon_processor_threads", float64(info.Processor.Threads))
+
+	e.gauge("falcon_memory_module_size_bytes", "Capacidade de cada módulo de memória em bytes.")
+	for _, mem := range info.Memory {
+		e.sample("falcon_memory_module_size_bytes", float64(mem.SizeMB)*1024*1024,
+			label{"slot", mem.Slot},
+			label{"manufacturer", mem.Manufacturer},
+			label{"serial", mem.SerialNumber},
+		)
+	}
+
+	e.gauge("falcon_disk_size_bytes", "Capacidade de cada disco em bytes.")
+	for _, hd := range info.HDs {
+		e.sample("falcon_disk_size_bytes", float64(hd.SizeBytes),
+			label{"device", hd.Name},
+			label{"model", hd.Model},
+			label{"serial", hd.Serial},
+		)
+	}
+
+	var mounted []model.PartitionInfo
+	for _, hd := range info.HDs {
+		for _, part := range hd.Partitions {
+			if part.MountPoint != "" {
+				mounted = append(mounted, part)
+			}
+		}
+	}
+	partLabels := func(part model.PartitionInfo) []label {
+		return []label{
+			{"device", part.Name},
+			{"fstype", part.Filesystem},
+			{"mountpoint", part.MountPoint},
+		}
+	}
+	e.gauge("falcon_filesystem_size_bytes", "Tamanho de cada partição montada em bytes.")
+	for _, part := range mounted {
+		e.sample("falcon_filesystem_size_bytes", float64(part.SizeBytes), partLabels(part)...)
+	}
+	e.gauge("falcon_filesystem_free_bytes", "Espaço livre de cada partição montada em bytes.")
+	for _, part := range mounted {
+		e.sample("falcon_filesystem_free_bytes", float64(part.FreeBytes), partLabels(part)...)
+	}
+
+	healthGauges := []struct {
+		name, help string
+		value      func(h model.DiskHealth) float64
+	}{
+		{"falcon_disk_health_passed", "Avaliação SMART de cada disco (1 aprovado, 0 reprovado).", func(h model.DiskHealth) float64 {
+			if h.Passed {
+				return 1
+			}
+			return 0
+		}},
+		{"falcon_disk_temperature_celsius", "Temperatura de cada disco em graus Celsius.", func(h model.DiskHealth) float64 { return float64(h.TemperatureC) }},
+		{"falcon_disk_power_on_hours", "Horas ligado de cada disco.", func(h model.DiskHealth) float64 { return float64(h.PowerOnHours) }},
+		{"falcon_disk_reallocated_sectors", "Setores realocados de cada disco.", func(h model.DiskHealth) float64 { return float64(h.ReallocatedSectors) }},
+		{"falcon_disk_media_errors", "Erros de mídia de cada disco NVMe.", func(h model.DiskHealth) float64 { return float64(h.MediaErrors) }},
+		{"falcon_disk_percentage_used", "Desgaste estimado de cada disco NVMe em porcentagem.", func(h model.DiskHealth) float64 { return float64(h.PercentageUsed) }},
+	}
+	for _, g := range healthGauges {
+		e.gauge(g.name, g.help)
+		for _, h := range info.DiskHealth {
+			e.sample(g.name, g.value(h),
+				label{"device", h.Device},
+				label{"model", h.Model},
+				label{"serial", h.Serial},
+			)
+		}
+	}
+
+	e.gauge("falcon_usb_devices", "Número de dispositivos USB conectados.")
+	e.sample("falcon_usb_devices", float64(len(info.USBDevices)))
+	e.gauge("falcon_usb_device_info", "Dispositivos USB conectados.")
+	for _, dev := range info.USBDevices {
+		e.sample("falcon_usb_device_info", 1,
+			label{"vendor_id", dev.VendorID},
+			label{"product_id", dev.ProductID},
+			label{"name", dev.Name},
+			label{"serial", dev.Serial},
+			label{"port", dev.Port},
+		)
+	}
+
+	e.gauge("falcon_software_packages", "Número de programas instalados por gerenciador de pacotes.")
+	bySource := make(map[string]int)
+	for _, pkg := range info.Software {
+		bySource[pkg.Source]++
+	}
+	sources := make([]string, 0, len(bySource))
+	for source := range bySource {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		e.sample("falcon_software_packages", float64(bySource[source]), label{"source", source})
+	}
+
+	if len(info.CollectorErrors) > 0 {
+		names := make([]string, 0, len(info.CollectorErrors))
+		for name := range info.CollectorErrors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		e.gauge("falcon_collector_failed", "Coletores de inventário que falharam na última coleta.")
+		for _, name := range names {
+			e.sample("falcon_collector_failed", 1, label{"collector", name})
+		}
+	}
+}
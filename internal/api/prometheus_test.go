@@ -0,0 +1,136 @@
+package api
+
+import (
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/metrics"
+	"github.com/dev/falcon-agent/internal/model"
+	"github.com/dev/falcon-agent/pkg/logger"
+)
+
+var update = flag.Bool("update", false, "regrava os arquivos de referência em testdata")
+
+func prometheusSource() *fakeSource {
+	now := time.Now()
+	m := metrics.NewSystemMetrics()
+	m.CPUCoreUsage = []*metrics.MetricHistory{metrics.NewMetricHistory(), metrics.NewMetricHistory()}
+	m.Record("cpu_usage", now, 12.5)
+	m.Record("memory_usage", now, 40)
+	m.Record("disk_read_bytes", now, 4096)
+	m.Record("cpu_core_0", now, 20)
+	m.Record("cpu_core_1", now, 5)
+
+	return &fakeSource{
+		metrics:   m,
+		processes: []metrics.ProcessSample{{PID: 1}, {PID: 2}},
+		info: &model.MachineInfo{
+			Hostname:     "estacao-01",
+			OS:           "linux",
+			SerialNumber: `SN "1"`,
+			OSDetails: model.OSInfo{
+				ID: "ubuntu", VersionID: "22.04", Kernel: "6.5.0", Arch: "x86_64",
+				BootTime: time.Unix(1700000000, 0),
+			},
+			BIOS:      model.BIOSInfo{Vendor: `Dell\Inc`, Version: "1.2.0"},
+			Processor: model.ProcessorInfo{Model: "Intel(R) Core(TM) i5", Cores: 4, Threads: 8},
+			Memory:    []model.MemoryInfo{{Slot: "DIMM1", SizeMB: 8192, Manufacturer: "Kingston", SerialNumber: "K1"}},
+			HDs: []model.HDInfo{{
+				Name: "nvme0n1", Model: "Samsung SSD 980", Serial: "S1", SizeGB: 465, SizeBytes: 500107862016,
+				Partitions: []model.PartitionInfo{
+					{Name: "nvme0n1p1", Filesystem: "vfat", MountPoint: "/boot/efi", SizeBytes: 536870912, FreeBytes: 500000000},
+					{Name: "nvme0n1p2", Filesystem: "swap"},
+				},
+			}},
+			USBDevices: []model.USBDevice{{VendorID: "046d", ProductID: "c52b", Name: "Receptor\nUnifying", Port: "1-1"}},
+			Software: []model.SoftwarePackage{
+				{Name: "bash", Source: model.SoftwareDpkg},
+				{Name: "firefox", Source: model.SoftwareSnap},
+				{Name: "vim", Source: model.SoftwareDpkg},
+			},
+			CollectorErrors: map[string]string{"disk_health": "smartctl não encontrado"},
+		},
+	}
+}
+
+func scrape(t *testing.T, source Source, accept string) (string, string) {
+	t.Helper()
+	s := New("127.0.0.1:0", source, logger.Default())
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/metrics = %d", rec.Code)
+	}
+	return rec.Header().Get("Content-Type"), rec.Body.String()
+}
+
+func TestPrometheusGolden(t *testing.T) {
+	contentType, body := scrape(t, prometheusSource(), "")
+	if contentType != prometheusContentType {
+		t.Errorf("Content-Type = %q, quer %q", contentType, prometheusContentType)
+	}
+
+	golden := filepath.Join("testdata", "metrics.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != string(want) {
+		t.Errorf("saída diferente de %s (go test -update para regravar):\n%s", golden, body)
+	}
+}
+
+func TestPrometheusOpenMetrics(t *testing.T) {
+	contentType, body := scrape(t, prometheusSource(), "application/openmetrics-text;version=1.0.0,text/plain;q=0.5")
+	if contentType != openMetricsContentType {
+		t.Errorf("Content-Type = %q, quer %q", contentType, openMetricsContentType)
+	}
+	if !strings.HasSuffix(body, "\n# EOF\n") {
+		t.Errorf("saída OpenMetrics sem # EOF no final")
+	}
+
+	if _, body := scrape(t, prometheusSource(), "text/plain"); strings.Contains(body, "# EOF") {
+		t.Errorf("# EOF no formato de texto do Prometheus")
+	}
+}
+
+func TestPrometheusOmitsEmptyFamilies(t *testing.T) {
+	// Sem amostras, núcleos, processos nem inventário
+	source := &fakeSource{metrics: metrics.NewSystemMetrics()}
+	if _, body := scrape(t, source, ""); body != "" {
+		t.Errorf("saída sem amostras = %q, quer vazia", body)
+	}
+
+	_, body := scrape(t, prometheusSource(), "")
+	lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "# TYPE ") {
+			continue
+		}
+		name := strings.Fields(line)[2]
+		if i+1 == len(lines) || !strings.HasPrefix(lines[i+1], name) {
+			t.Errorf("família %s sem amostras", name)
+		}
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("C:\\dir \"x\"\nfim"); got != `C:\\dir \"x\"\nfim` {
+		t.Errorf("escapeLabel = %q", got)
+	}
+}
@@ -0,0 +1,336 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/diskhealth"
+	"github.com/dev/falcon-agent/internal/inventory"
+	"github.com/dev/falcon-agent/internal/metrics"
+	"github.com/dev/falcon-agent/internal/model"
+	"github.com/dev/falcon-agent/pkg/logger"
+)
+
+// Source fornece os dados expostos pela API
+type Source interface {
+	MachineInfo() *model.MachineInfo
+	Metrics() *metrics.SystemMetrics
+	// MetricStore pode retornar nil quando não há armazenamento em disco
+	MetricStore() *metrics.Store
+	// InventoryChanges retorna as últimas mudanças do inventário
+	InventoryChanges(limit int) ([]inventory.Change, error)
+	// DiskHealthHistory retorna as leituras de saúde gravadas de um disco
+	DiskHealthHistory(serial string) ([]diskhealth.Sample, error)
+	// DiskHealthEvents retorna os últimos avisos de piora na saúde dos discos
+	DiskHealthEvents(limit int) ([]diskhealth.Event, error)
+	// Processes retorna os processos da última amostra
+	Processes() []metrics.ProcessSample
+}
+
+// Server é o servidor HTTP REST embutido no agente
+type Server struct {
+	source Source
+	logger logger.Logger
+	server *http.Server
+}
+
+// New cria um novo servidor da API escutando em addr
+func New(addr string, source Source, log logger.Logger) *Server {
+	s := &Server{
+		source: source,
+		logger: log,
+	}
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s
+}
+
+// Handler retorna o roteador com todos os endpoints da API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /metrics", s.handlePrometheus)
+	mux.HandleFunc("GET /v1/machine", s.handleMachine)
+	mux.HandleFunc("GET /v1/usb", s.handleUSB)
+	mux.HandleFunc("GET /v1/disks", s.handleDisks)
+	mux.HandleFunc("GET /v1/disks/health", s.handleDiskHealth)
+	mux.HandleFunc("GET /v1/disks/health/events", s.handleDiskHealthEvents)
+	mux.HandleFunc("GET /v1/disks/{serial}/health", s.handleDiskHealthHistory)
+	mux.HandleFunc("GET /v1/network", s.handleNetwork)
+	mux.HandleFunc("GET /v1/software", s.handleSoftware)
+	mux.HandleFunc("GET /v1/processes", s.handleProcesses)
+	mux.HandleFunc("GET /v1/inventory/changes", s.handleInventoryChanges)
+	mux.HandleFunc("GET /v1/metrics", s.handleMetrics)
+	mux.HandleFunc("GET /v1/metrics/{name}/history", s.handleMetricHistory)
+	return mux
+}
+
+// Start abre o socket de escuta e atende as requisições em segundo plano
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("erro ao escutar em %s: %v", s.server.Addr, err)
+	}
+
+	s.logger.Info("API HTTP escutando em %s", listener.Addr())
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Erro no servidor da API: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop encerra o servidor aguardando as requisições em andamento
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleMachine(w http.ResponseWriter, r *http.Request) {
+	info, ok := s.machineInfo(w)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (s *Server) handleUSB(w http.ResponseWriter, r *http.Request) {
+	info, ok := s.machineInfo(w)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, emptyIfNil(info.USBDevices))
+}
+
+func (s *Server) handleDisks(w http.ResponseWriter, r *http.Request) {
+	info, ok := s.machineInfo(w)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, emptyIfNil(info.HDs))
+}
+
+func (s *Server) handleDiskHealth(w http.ResponseWriter, r *http.Request) {
+	info, ok := s.machineInfo(w)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, emptyIfNil(info.DiskHealth))
+}
+
+// handleDiskHealthEvents retorna os avisos de piora na saúde dos discos.
+// Parâmetro: limit (padrão 100; 0 retorna todos).
+func (s *Server) handleDiskHealthEvents(w http.ResponseWriter, r *http.Request) {
+	limit, ok := limitParam(w, r)
+	if !ok {
+		return
+	}
+
+	events, err := s.source.DiskHealthEvents(limit)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if events == nil {
+		events = []diskhealth.Event{}
+	}
+	writeJSON(w, http.StatusOK, events)
+}
+
+// handleDiskHealthHistory retorna as leituras de saúde gravadas do disco
+// com o serial informado
+func (s *Server) handleDiskHealthHistory(w http.ResponseWriter, r *http.Request) {
+	samples, err := s.source.DiskHealthHistory(r.PathValue("serial"))
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if len(samples) == 0 {
+		writeError(w, http.StatusNotFound, "nenhuma leitura de saúde para o disco "+r.PathValue("serial"))
+		return
+	}
+	writeJSON(w, http.StatusOK, samples)
+}
+
+func (s *Server) handleNetwork(w http.ResponseWriter, r *http.Request) {
+	info, ok := s.machineInfo(w)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, info.Network)
+}
+
+// handleSoftware retorna os programas instalados. Parâmetro: q filtra
+// pelo nome ou fornecedor, sem diferenciar maiúsculas de minúsculas.
+func (s *Server) handleSoftware(w http.ResponseWriter, r *http.Request) {
+	info, ok := s.machineInfo(w)
+	if !ok {
+		return
+	}
+	query := strings.ToLower(r.URL.Query().Get("q"))
+	packages := make([]model.SoftwarePackage, 0, len(info.Software))
+	for _, pkg := range info.Software {
+		if strings.Contains(strings.ToLower(pkg.Name), query) || strings.Contains(strings.ToLower(pkg.Vendor), query) {
+			packages = append(packages, pkg)
+		}
+	}
+	writeJSON(w, http.StatusOK, packages)
+}
+
+// handleProcesses retorna os processos em execução. Parâmetros: sort
+// ("cpu", "memory", "files", "pid" ou "name"; padrão "cpu"), q filtra por
+// PID, usuário, nome ou linha de comando e limit (padrão 100, 0 para todos).
+func (s *Server) handleProcesses(w http.ResponseWriter, r *http.Request) {
+	limit, ok := limitParam(w, r)
+	if !ok {
+		return
+	}
+	sortBy := r.URL.Query().Get("sort")
+	if sortBy == "" {
+		sortBy = metrics.SortByCPU
+	}
+
+	processes := metrics.FilterProcesses(s.source.Processes(), r.URL.Query().Get("q"))
+	if !metrics.SortProcesses(processes, sortBy) {
+		writeError(w, http.StatusBadRequest, "parâmetro sort inválido: "+sortBy)
+		return
+	}
+	if limit > 0 && len(processes) > limit {
+		processes = processes[:limit]
+	}
+	if processes == nil {
+		processes = []metrics.ProcessSample{}
+	}
+	writeJSON(w, http.StatusOK, processes)
+}
+
+// handleInventoryChanges retorna o histórico de mudanças do inventário.
+// Parâmetro: limit (padrão 100; 0 retorna todo o histórico).
+func (s *Server) handleInventoryChanges(w http.ResponseWriter, r *http.Request) {
+	limit, ok := limitParam(w, r)
+	if !ok {
+		return
+	}
+
+	changes, err := s.source.InventoryChanges(limit)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if changes == nil {
+		changes = []inventory.Change{}
+	}
+	writeJSON(w, http.StatusOK, changes)
+}
+
+// limitParam lê o parâmetro limit (padrão 100), respondendo com erro
+// quando ele é inválido
+func limitParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return 100, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		writeError(w, http.StatusBadRequest, "parâmetro limit inválido: "+v)
+		return 0, false
+	}
+	return n, true
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.source.Metrics())
+}
+
+// handleMetricHistory consulta o armazenamento em disco. Parâmetros:
+// from e to em RFC 3339 (padrão: últimas 24 horas) e resolution
+// ("raw", "1m" ou "1h"; padrão "1m").
+func (s *Server) handleMetricHistory(w http.ResponseWriter, r *http.Request) {
+	store := s.source.MetricStore()
+	if store == nil {
+		writeError(w, http.StatusServiceUnavailable, "armazenamento de métricas indisponível")
+		return
+	}
+
+	query := r.URL.Query()
+	to := time.Now()
+	from := to.Add(-24 * time.Hour)
+	var err error
+	if v := query.Get("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			writeError(w, http.StatusBadRequest, "parâmetro from inválido: "+v)
+			return
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			writeError(w, http.StatusBadRequest, "parâmetro to inválido: "+v)
+			return
+		}
+	}
+
+	resolution := metrics.ResolutionMinute
+	if v := query.Get("resolution"); v != "" {
+		if resolution, err = metrics.ParseResolution(v); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	points, err := store.Query(r.PathValue("name"), from, to, resolution)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if points == nil {
+		points = []metrics.Aggregate{}
+	}
+	writeJSON(w, http.StatusOK, points)
+}
+
+// machineInfo responde 503 enquanto a primeira coleta não terminou
+func (s *Server) machineInfo(w http.ResponseWriter) (*model.MachineInfo, bool) {
+	info := s.source.MachineInfo()
+	if info == nil {
+		writeError(w, http.StatusServiceUnavailable, "informações da máquina ainda não coletadas")
+		return nil, false
+	}
+	return info, true
+}
+
+// emptyIfNil troca a lista nil por uma vazia, para que a resposta seja
+// [] e não null
+func emptyIfNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/diskhealth"
+	"github.com/dev/falcon-agent/internal/inventory"
+	"github.com/dev/falcon-agent/internal/metrics"
+	"github.com/dev/falcon-agent/internal/model"
+	"github.com/dev/falcon-agent/pkg/logger"
+)
+
+type fakeSource struct {
+	info      *model.MachineInfo
+	metrics   *metrics.SystemMetrics
+	store     *metrics.Store
+	changes   []inventory.Change
+	processes []metrics.ProcessSample
+	// limit é o último limite recebido pelas consultas de histórico
+	limit int
+}
+
+func (s *fakeSource) MachineInfo() *model.MachineInfo    { return s.info }
+func (s *fakeSource) Metrics() *metrics.SystemMetrics    { return s.metrics }
+func (s *fakeSource) MetricStore() *metrics.Store        { return s.store }
+func (s *fakeSource) Processes() []metrics.ProcessSample { return s.processes }
+
+func (s *fakeSource) InventoryChanges(limit int) ([]inventory.Change, error) {
+	s.limit = limit
+	return s.changes, nil
+}
+
+func (s *fakeSource) DiskHealthHistory(serial string) ([]diskhealth.Sample, error) {
+	return nil, nil
+}
+
+func (s *fakeSource) DiskHealthEvents(limit int) ([]diskhealth.Event, error) {
+	s.limit = limit
+	return nil, errors.New("histórico indisponível")
+}
+
+func newTestSource() *fakeSource {
+	return &fakeSource{
+		info:    &model.MachineInfo{Hostname: "estacao-01"},
+		metrics: metrics.NewSystemMetrics(),
+		processes: []metrics.ProcessSample{
+			{PID: 10, Name: "bash", CPUPercent: 1},
+			{PID: 20, Name: "firefox", CPUPercent: 30, RSSBytes: 500},
+			{PID: 30, Name: "agent", CPUPercent: 5, RSSBytes: 900},
+		},
+	}
+}
+
+// get faz a requisição e retorna o status e o corpo
+func get(t *testing.T, source Source, target string) (int, string) {
+	t.Helper()
+	s := New("127.0.0.1:0", source, logger.Default())
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec.Code, rec.Body.String()
+}
+
+func TestMachineEndpointsBeforeFirstCollection(t *testing.T) {
+	source := newTestSource()
+	source.info = nil
+	for _, target := range []string{"/v1/machine", "/v1/usb", "/v1/disks", "/v1/disks/health", "/v1/network", "/v1/software"} {
+		if code, body := get(t, source, target); code != http.StatusServiceUnavailable || !strings.Contains(body, `"error"`) {
+			t.Errorf("%s = %d %s, quer 503 com erro", target, code, body)
+		}
+	}
+	if code, _ := get(t, source, "/healthz"); code != http.StatusOK {
+		t.Errorf("/healthz = %d antes da primeira coleta", code)
+	}
+}
+
+func TestEmptyListsAreArrays(t *testing.T) {
+	source := newTestSource()
+	source.processes = nil
+	for _, target := range []string{"/v1/usb", "/v1/disks", "/v1/disks/health", "/v1/software", "/v1/processes", "/v1/inventory/changes"} {
+		code, body := get(t, source, target)
+		if code != http.StatusOK || strings.TrimSpace(body) != "[]" {
+			t.Errorf("%s = %d %q, quer 200 []", target, code, body)
+		}
+	}
+}
+
+func TestLimitParam(t *testing.T) {
+	source := newTestSource()
+	tests := []struct {
+		query string
+		code  int
+		limit int
+	}{
+		{"", http.StatusOK, 100},
+		{"?limit=5", http.StatusOK, 5},
+		{"?limit=0", http.StatusOK, 0},
+		{"?limit=-1", http.StatusBadRequest, -1},
+		{"?limit=abc", http.StatusBadRequest, -1},
+	}
+	for _, tt := range tests {
+		source.limit = -1
+		code, body := get(t, source, "/v1/inventory/changes"+tt.query)
+		if code != tt.code || source.limit != tt.limit {
+			t.Errorf("limit %q = %d (limite %d), quer %d (limite %d): %s", tt.query, code, source.limit, tt.code, tt.limit, body)
+		}
+	}
+
+	// Falhas ao ler o histórico em disco respondem 503
+	if code, _ := get(t, source, "/v1/disks/health/events?limit=2"); code != http.StatusServiceUnavailable || source.limit != 2 {
+		t.Errorf("/v1/disks/health/events = %d (limite %d), quer 503 (limite 2)", code, source.limit)
+	}
+}
+
+func TestProcessesEndpoint(t *testing.T) {
+	source := newTestSource()
+	pids := func(body string) []int32 {
+		t.Helper()
+		var processes []metrics.ProcessSample
+		if err := json.Unmarshal([]byte(body), &processes); err != nil {
+			t.Fatal(err)
+		}
+		var pids []int32
+		for _, p := range processes {
+			pids = append(pids, p.PID)
+		}
+		return pids
+	}
+
+	tests := []struct {
+		query string
+		want  []int32
+	}{
+		{"", []int32{20, 30, 10}},
+		{"?sort=memory&limit=2", []int32{30, 20}},
+		{"?sort=pid&q=fire", []int32{20}},
+	}
+	for _, tt := range tests {
+		code, body := get(t, source, "/v1/processes"+tt.query)
+		if code != http.StatusOK {
+			t.Fatalf("%s = %d %s", tt.query, code, body)
+		}
+		if got := pids(body); len(got) != len(tt.want) || got[0] != tt.want[0] || got[len(got)-1] != tt.want[len(tt.want)-1] {
+			t.Errorf("%s = %v, quer %v", tt.query, got, tt.want)
+		}
+	}
+
+	if code, body := get(t, source, "/v1/processes?sort=ram"); code != http.StatusBadRequest || !strings.Contains(body, "sort") {
+		t.Errorf("sort inválido = %d %s, quer 400", code, body)
+	}
+}
+
+func TestMetricHistoryEndpoint(t *testing.T) {
+	source := newTestSource()
+	if code, _ := get(t, source, "/v1/metrics/cpu_usage/history"); code != http.StatusServiceUnavailable {
+		t.Errorf("sem armazenamento = %d, quer 503", code)
+	}
+
+	store, err := metrics.OpenStore(t.TempDir(), metrics.DefaultStoreOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	source.store = store
+	base := time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+	for i, v := range []float64{10, 20, 30} {
+		if err := store.Append("cpu_usage", metrics.MetricPoint{Timestamp: base.Add(time.Duration(i) * time.Minute), Value: v}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	from, to := base.Format(time.RFC3339), base.Add(time.Hour).Format(time.RFC3339)
+	tests := []struct {
+		target string
+		code   int
+		points int
+	}{
+		{"/v1/metrics/cpu_usage/history?resolution=raw&from=" + from + "&to=" + to, http.StatusOK, 3},
+		{"/v1/metrics/cpu_usage/history?resolution=1h&from=" + from + "&to=" + to, http.StatusOK, 1},
+		{"/v1/metrics/cpu_usage/history", http.StatusOK, 3},
+		{"/v1/metrics/memory_usage/history", http.StatusOK, 0},
+		{"/v1/metrics/cpu_usage/history?from=ontem", http.StatusBadRequest, 0},
+		{"/v1/metrics/cpu_usage/history?to=2024-13-01", http.StatusBadRequest, 0},
+		{"/v1/metrics/cpu_usage/history?resolution=5m", http.StatusBadRequest, 0},
+		{"/v1/metrics/..%2Fcpu/history", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		code, body := get(t, source, tt.target)
+		if code != tt.code {
+			t.Errorf("%s = %d %s, quer %d", tt.target, code, body, tt.code)
+			continue
+		}
+		if code != http.StatusOK {
+			continue
+		}
+		var points []metrics.Aggregate
+		if err := json.Unmarshal([]byte(body), &points); err != nil || points == nil || len(points) != tt.points {
+			t.Errorf("%s = %s, quer %d pontos", tt.target, body, tt.points)
+		}
+	}
+}
@@ -17,8 +17,29 @@ type TimeValue struct {
 	Value float64
 }
 
+// Series é uma linha de um gráfico com várias séries
+type Series struct {
+	Label string
+	Data  []TimeValue
+	Color color.Color
+}
+
+var defaultColor = color.RGBA{R: 0, G: 0, B: 255, A: 255}
+
 func CreateLineChart(data []TimeValue, title, yLabel string) (image.Image, error) {
-	if len(data) == 0 {
+	return CreateMultiLineChart([]Series{{Data: data, Color: defaultColor}}, title, yLabel)
+}
+
+// CreateMultiLineChart desenha várias séries no mesmo gráfico, com legenda
+// quando as séries têm rótulo. O eixo X mostra o horário dos pontos.
+func CreateMultiLineChart(series []Series, title, yLabel string) (image.Image, error) {
+	empty := true
+	for _, s := range series {
+		if len(s.Data) > 0 {
+			empty = false
+		}
+	}
+	if empty {
 		return nil, nil
 	}
 
@@ -35,25 +56,35 @@ func CreateLineChart(data []TimeValue, title, yLabel string) (image.Image, error
 	p.Y.Label.TextStyle.Color = color.Black
 	p.X.Color = color.Black
 	p.Y.Color = color.Black
-
-	// Criar os pontos para o gráfico
-	pts := make(plotter.XYs, len(data))
-	minTime := data[0].Time
-	for i, d := range data {
-		pts[i].X = d.Time.Sub(minTime).Seconds()
-		pts[i].Y = d.Value
+	p.X.Tick.Marker = plot.TimeTicks{Format: "15:04", Time: plot.UnixTimeIn(time.Local)}
+
+	for _, s := range series {
+		if len(s.Data) == 0 {
+			continue
+		}
+
+		// Criar os pontos para o gráfico
+		pts := make(plotter.XYs, len(s.Data))
+		for i, d := range s.Data {
+			pts[i].X = float64(d.Time.Unix())
+			pts[i].Y = d.Value
+		}
+
+		// Criar a linha
+		line, err := plotter.NewLine(pts)
+		if err != nil {
+			return nil, err
+		}
+		line.Color = s.Color
+		line.Width = vg.Points(2)
+
+		// Adicionar a linha ao gráfico
+		p.Add(line)
+		if s.Label != "" {
+			p.Legend.Add(s.Label, line)
+		}
 	}
-
-	// Criar a linha
-	line, err := plotter.NewLine(pts)
-	if err != nil {
-		return nil, err
-	}
-	line.Color = color.RGBA{R: 0, G: 0, B: 255, A: 255}
-	line.Width = vg.Points(2)
-
-	// Adicionar a linha ao gráfico
-	p.Add(line)
+	p.Legend.Top = true
 
 	// Adicionar grade
 	p.Add(plotter.NewGrid())
@@ -76,3 +107,19 @@ func CreateCPUChart(data []TimeValue) (image.Image, error) {
 func CreateMemoryChart(data []TimeValue) (image.Image, error) {
 	return CreateLineChart(data, "Uso de Memória", "Porcentagem (%)")
 }
+
+// CreateDiskIOChart desenha as taxas de leitura e escrita em disco (KB/s)
+func CreateDiskIOChart(read, write []TimeValue) (image.Image, error) {
+	return CreateMultiLineChart([]Series{
+		{Label: "Leitura", Data: read, Color: defaultColor},
+		{Label: "Escrita", Data: write, Color: color.RGBA{R: 220, G: 0, B: 0, A: 255}},
+	}, "E/S de Disco", "KB/s")
+}
+
+// CreateNetworkChart desenha o tráfego de rede recebido e enviado (KB/s)
+func CreateNetworkChart(recv, sent []TimeValue) (image.Image, error) {
+	return CreateMultiLineChart([]Series{
+		{Label: "Recebido", Data: recv, Color: color.RGBA{R: 0, G: 150, B: 0, A: 255}},
+		{Label: "Enviado", Data: sent, Color: color.RGBA{R: 255, G: 140, B: 0, A: 255}},
+	}, "Rede", "KB/s")
+}
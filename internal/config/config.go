@@ -1,9 +1,19 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/metrics"
+	"github.com/dev/falcon-agent/pkg/logger"
 )
 
 // Config representa a configuração do agente
@@ -12,12 +22,113 @@ type Config struct {
 	DataPath   string
 	ConfigPath string
 	Platform   string
+	// ConfigFile é o arquivo de configuração carregado (vazio se nenhum)
+	ConfigFile string
+	// Headless executa o agente como daemon, sem interface gráfica
+	Headless bool
+	// LogLevel é o nível mínimo das mensagens de log (debug, info, warn ou error)
+	LogLevel string
+	// LogFormat é o formato das linhas de log (text ou json)
+	LogFormat string
+	// LogRotation define quando o arquivo de log é rotacionado e por quanto
+	// tempo os arquivos antigos são mantidos
+	LogRotation logger.RotateOptions
+	// LogSinks são os destinos do log: file, syslog e journald
+	LogSinks []string
+	// LogSyslog é o destino e a facilidade das mensagens enviadas ao syslog
+	LogSyslog logger.SyslogOptions
+	// APIAddr é o endereço de escuta da API HTTP (vazio desativa a API)
+	APIAddr string
+	// USBPolicyPath é o arquivo JSON com a política de controle de USB
+	USBPolicyPath string
+	// MetricsInterval é o intervalo entre as amostras de CPU, memória e carga
+	MetricsInterval time.Duration
+	// MetricsRetention define por quanto tempo as métricas ficam em disco,
+	// por resolução (dados brutos, agregados por minuto e por hora)
+	MetricsRetention metrics.StoreOptions
+	// TopProcesses é o número de processos com maior uso de CPU guardados a
+	// cada amostra (0 desativa a leitura dos processos)
+	TopProcesses int
+	// InventoryInterval é o intervalo entre coletas completas do inventário
+	InventoryInterval time.Duration
+	// DisabledCollectors são os coletores de inventário desativados
+	DisabledCollectors []string
+	// CollectorTimeouts substitui o timeout padrão de cada coletor
+	CollectorTimeouts map[string]time.Duration
+	// Disks define quais discos entram no inventário
+	Disks DiskFilter
+	// ServerURL é o endereço do servidor central (vazio desativa o envio)
+	ServerURL string
+	// EnrollmentToken é o token usado no registro do agente no servidor
+	EnrollmentToken string
+	// ReportInterval é o intervalo entre os envios ao servidor. Deve ser
+	// menor que a janela do histórico em memória para que nenhum ponto de
+	// métrica deixe de ser enviado.
+	ReportInterval time.Duration
+	// SpoolMaxBytes limita a fila em disco dos relatórios ainda não
+	// enviados; ao ultrapassar o limite os mais antigos são descartados
+	SpoolMaxBytes int64
+	// ExportDir é o diretório onde as exportações são gravadas
+	ExportDir string
+	// ExportFormat é o formato das exportações (csv ou json)
+	ExportFormat string
+
+	// args e path guardam as flags e o arquivo usados em Load, para que a
+	// configuração possa ser recarregada das mesmas fontes
+	args []string
+	path string
+}
+
+// DiskFilter define quais discos o coletor de discos inclui no inventário
+type DiskFilter struct {
+	// MinSizeGB ignora os discos menores que o tamanho (0 inclui todos)
+	MinSizeGB int
+	// Removable inclui os discos removíveis, como pendrives e cartões
+	Removable bool
+	// Exclude são padrões do nome do dispositivo a ignorar (ex.: "loop*")
+	Exclude []string
 }
 
-// New retorna uma nova configuração baseada no sistema operacional
+// DefaultDiskFilter mantém o inventário de discos anterior ao filtro:
+// apenas discos fixos de pelo menos 10 GB, sem dispositivos de loop, de
+// memória, unidades de disquete e ópticas e os volumes lógicos montados
+// sobre os discos (device-mapper, RAID por software, NBD e ZFS). Pendrives, cartões e discos
+// menores são incluídos apenas com MinSizeGB e Removable configurados.
+func DefaultDiskFilter() DiskFilter {
+	return DiskFilter{
+		MinSizeGB: 10,
+		Removable: false,
+		Exclude:   []string{"loop*", "ram*", "zram*", "fd*", "sr*", "dm-*", "md*", "nbd*", "zd*"},
+	}
+}
+
+// DefaultAPIAddr é o endereço padrão da API HTTP embutida
+const DefaultAPIAddr = "127.0.0.1:8080"
+
+// ConfigFileName é o nome do arquivo de configuração em ConfigPath
+const ConfigFileName = "config.yaml"
+
+// New retorna a configuração padrão, com os caminhos relativos ao
+// diretório atual
 func New() *Config {
 	config := &Config{
-		Platform: runtime.GOOS,
+		Platform:    runtime.GOOS,
+		LogLevel:    "info",
+		LogFormat:   "text",
+		LogRotation: logger.DefaultRotateOptions(),
+		LogSinks:    []string{logger.SinkFile},
+		LogSyslog:   logger.SyslogOptions{Facility: "daemon"},
+		APIAddr:     DefaultAPIAddr,
+
+		MetricsInterval:   5 * time.Second,
+		MetricsRetention:  metrics.DefaultStoreOptions(),
+		TopProcesses:      10,
+		InventoryInterval: time.Minute,
+		CollectorTimeouts: map[string]time.Duration{},
+		Disks:             DefaultDiskFilter(),
+		ReportInterval:    time.Minute,
+		SpoolMaxBytes:     64 << 20,
+		ExportFormat:      "csv",
 	}
 
 	// Obtém o diretório atual
@@ -26,16 +137,80 @@ func New() *Config {
 		currentDir = "."
 	}
 
-	// Define os caminhos base dependendo do sistema operacional
-	if runtime.GOOS == "windows" {
-		config.LogPath = filepath.Join(currentDir, "logs")
-		config.DataPath = filepath.Join(currentDir, "data")
-		config.ConfigPath = filepath.Join(currentDir, "config")
-	} else {
-		config.LogPath = filepath.Join(currentDir, "logs")
-		config.DataPath = filepath.Join(currentDir, "data")
-		config.ConfigPath = filepath.Join(currentDir, "config")
-	}
+	config.LogPath = filepath.Join(currentDir, "logs")
+	config.DataPath = filepath.Join(currentDir, "data")
+	config.ConfigPath = filepath.Join(currentDir, "config")
+	config.USBPolicyPath = filepath.Join(config.ConfigPath, "usb-policy.json")
+	config.ExportDir = filepath.Join(currentDir, "exports")
 
 	return config
 }
+
+// Validate verifica a configuração já combinada de todas as fontes. Cada
+// problema é reportado como um *KeyError com o nome da chave.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(key string, ok bool, format string, v ...any) {
+		if !ok {
+			errs = append(errs, &KeyError{Key: key, Err: fmt.Errorf(format, v...)})
+		}
+	}
+
+	check("log.level", validLogLevels[c.LogLevel], "nível inválido %q (use debug, info, warn ou error)", c.LogLevel)
+	check("log.format", c.LogFormat == "text" || c.LogFormat == "json", "formato inválido %q (use text ou json)", c.LogFormat)
+	check("log.path", c.LogPath != "", "não pode ser vazio")
+	check("log.max_size_mb", c.LogRotation.MaxSize > 0, "deve ser positivo")
+	check("log.max_backups", c.LogRotation.MaxBackups >= 0, "não pode ser negativo")
+	check("log.max_age", c.LogRotation.MaxAge >= 0, "não pode ser negativa")
+	check("log.sinks", len(c.LogSinks) > 0, "informe ao menos um destino (file, syslog ou journald)")
+	for _, sink := range c.LogSinks {
+		check("log.sinks", validLogSinks[sink], "destino inválido %q (use file, syslog ou journald)", sink)
+	}
+	if slices.Contains(c.LogSinks, logger.SinkSyslog) {
+		_, _, err := logger.ParseSyslogAddr(c.LogSyslog.Addr)
+		check("log.syslog.addr", err == nil, "%v", err)
+		_, err = logger.ParseFacility(c.LogSyslog.Facility)
+		check("log.syslog.facility", err == nil, "facilidade inválida %q (ex.: daemon, local0)", c.LogSyslog.Facility)
+	}
+	check("data_path", c.DataPath != "", "não pode ser vazio")
+
+	if c.APIAddr != "" {
+		_, _, err := net.SplitHostPort(c.APIAddr)
+		check("api.addr", err == nil, "endereço inválido %q (use host:porta)", c.APIAddr)
+	}
+
+	check("metrics.interval", c.MetricsInterval >= time.Second, "deve ser de pelo menos 1s")
+	check("metrics.retention.raw", c.MetricsRetention.RawRetention >= time.Hour, "deve ser de pelo menos 1h")
+	check("metrics.retention.minute", c.MetricsRetention.MinuteRetention >= 0, "não pode ser negativa")
+	check("metrics.retention.hour", c.MetricsRetention.HourRetention >= 0, "não pode ser negativa")
+	check("metrics.top_processes", c.TopProcesses >= 0, "não pode ser negativo")
+	check("inventory.interval", c.InventoryInterval >= 10*time.Second, "deve ser de pelo menos 10s")
+
+	for name, timeout := range c.CollectorTimeouts {
+		check("collectors.timeouts."+name, timeout > 0, "deve ser positivo")
+	}
+	check("collectors.disks.min_size_gb", c.Disks.MinSizeGB >= 0, "não pode ser negativo")
+	for _, pattern := range c.Disks.Exclude {
+		_, err := path.Match(pattern, "")
+		check("collectors.disks.exclude", err == nil, "padrão inválido %q", pattern)
+	}
+
+	if c.ServerURL != "" {
+		u, err := url.Parse(c.ServerURL)
+		check("server.url", err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"URL inválida %q", c.ServerURL)
+	}
+	window := time.Duration(metrics.HistorySize) * c.MetricsInterval
+	check("server.report_interval", c.ReportInterval > 0 && c.ReportInterval < window,
+		"deve ser positivo e menor que %v (janela do histórico em memória)", window)
+	check("server.spool_max_mb", c.SpoolMaxBytes > 0, "deve ser positivo")
+
+	check("export.format", c.ExportFormat == "csv" || c.ExportFormat == "json",
+		"formato inválido %q (use csv ou json)", c.ExportFormat)
+
+	return errors.Join(errs...)
+}
+
+var validLogLevels = map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+
+var validLogSinks = map[string]bool{logger.SinkFile: true, logger.SinkSyslog: true, logger.SinkJournal: true}
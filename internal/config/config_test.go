@@ -0,0 +1,161 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), ConfigFileName)
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	filename := writeConfig(t, `
+log:
+  level: debug
+metrics:
+  interval: 10s
+collectors:
+  disabled: [usb, bios]
+  timeouts:
+    disks: 20s
+`)
+	t.Setenv("FALCON_LOG_LEVEL", "warn")
+
+	cfg, err := Load([]string{"--config", filename, "--metrics-interval", "20s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ConfigFile != filename {
+		t.Errorf("ConfigFile = %q, quer %q", cfg.ConfigFile, filename)
+	}
+	// Flags vencem o ambiente, que vence o arquivo
+	if cfg.MetricsInterval != 20*time.Second {
+		t.Errorf("MetricsInterval = %v, quer 20s", cfg.MetricsInterval)
+	}
+	if cfg.LogLevel != "warn" {
+		t.Errorf("LogLevel = %q, quer warn", cfg.LogLevel)
+	}
+	if len(cfg.DisabledCollectors) != 2 || cfg.DisabledCollectors[1] != "bios" {
+		t.Errorf("DisabledCollectors = %v", cfg.DisabledCollectors)
+	}
+	if cfg.CollectorTimeouts["disks"] != 20*time.Second {
+		t.Errorf("CollectorTimeouts = %v", cfg.CollectorTimeouts)
+	}
+	// Chaves ausentes mantêm o padrão
+	if cfg.InventoryInterval != time.Minute {
+		t.Errorf("InventoryInterval = %v, quer o padrão de 1m", cfg.InventoryInterval)
+	}
+}
+
+func TestLoadExampleConfig(t *testing.T) {
+	if _, err := Load([]string{"--config", filepath.Join("..", "..", "deploy", "config.example.yaml")}); err != nil {
+		t.Fatalf("exemplo de configuração inválido: %v", err)
+	}
+}
+
+func TestLoadErrorsPointAtKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		args    []string
+		key     string
+		source  string
+	}{
+		{"chave desconhecida", "metrics:\n  intervalo: 10s\n", nil, "metrics.intervalo", ":2"},
+		{"duração inválida", "metrics:\n  interval: abc\n", nil, "metrics.interval", ":2"},
+		{"flag inválida", "", []string{"--inventory-interval", "x"}, "inventory.interval", "--inventory-interval"},
+		{"validação", "inventory:\n  interval: 1s\n", nil, "inventory.interval", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"--config", writeConfig(t, tt.content)}, tt.args...)
+			_, err := Load(args)
+			var keyErr *KeyError
+			if !errors.As(err, &keyErr) {
+				t.Fatalf("Load = %v, quer *KeyError", err)
+			}
+			if keyErr.Key != tt.key || !strings.Contains(keyErr.Source, tt.source) {
+				t.Errorf("erro em %s (%s), quer %s (%s)", keyErr.Key, keyErr.Source, tt.key, tt.source)
+			}
+		})
+	}
+}
+
+func TestLoadExplicitFileMustExist(t *testing.T) {
+	if _, err := Load([]string{"--config", filepath.Join(t.TempDir(), "ausente.yaml")}); err == nil {
+		t.Error("Load aceitou arquivo inexistente indicado por --config")
+	}
+}
+
+func TestEnrollmentTokenIsNotAFlag(t *testing.T) {
+	filename := writeConfig(t, "")
+	if _, err := Load([]string{"--config", filename, "--server-enrollment-token", "segredo"}); err == nil {
+		t.Error("token de registro aceito como flag")
+	}
+
+	t.Setenv("FALCON_SERVER_ENROLLMENT_TOKEN", "segredo")
+	cfg, err := Load([]string{"--config", filename})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.EnrollmentToken != "segredo" {
+		t.Errorf("EnrollmentToken = %q, quer o valor do ambiente", cfg.EnrollmentToken)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := New().Validate(); err != nil {
+		t.Fatalf("configuração padrão inválida: %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		modify func(c *Config)
+	}{
+		{"log.level", func(c *Config) { c.LogLevel = "verbose" }},
+		{"log.sinks", func(c *Config) { c.LogSinks = nil }},
+		{"api.addr", func(c *Config) { c.APIAddr = "8080" }},
+		{"metrics.interval", func(c *Config) { c.MetricsInterval = 100 * time.Millisecond }},
+		{"metrics.top_processes", func(c *Config) { c.TopProcesses = -1 }},
+		{"collectors.disks.exclude", func(c *Config) { c.Disks.Exclude = []string{"sd["} }},
+		{"server.url", func(c *Config) { c.ServerURL = "inventario.empresa.com" }},
+		{"server.report_interval", func(c *Config) { c.ReportInterval = 24 * time.Hour }},
+		{"export.format", func(c *Config) { c.ExportFormat = "xml" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			c := New()
+			tt.modify(c)
+			var keyErr *KeyError
+			if err := c.Validate(); !errors.As(err, &keyErr) || keyErr.Key != tt.key {
+				t.Errorf("Validate = %v, quer erro em %s", err, tt.key)
+			}
+		})
+	}
+}
+
+func TestDiffMasksSecrets(t *testing.T) {
+	old, new := New(), New()
+	new.EnrollmentToken = "segredo"
+	new.MetricsInterval = 10 * time.Second
+
+	changes := Diff(old, new)
+	if len(changes) != 2 {
+		t.Fatalf("Diff = %v, quer 2 mudanças", changes)
+	}
+	for _, ch := range changes {
+		if strings.Contains(ch.String(), "segredo") {
+			t.Errorf("valor sensível exposto: %s", ch)
+		}
+	}
+}
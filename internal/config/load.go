@@ -0,0 +1,535 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix é o prefixo das variáveis de ambiente de configuração. A
+// variável de cada chave é o prefixo seguido da chave em maiúsculas, com
+// pontos trocados por "_" (ex.: metrics.interval → FALCON_METRICS_INTERVAL).
+const EnvPrefix = "FALCON_"
+
+// KeyError é um erro de configuração associado a uma chave
+type KeyError struct {
+	Key string
+	// Source indica a origem do valor: arquivo e linha, variável de
+	// ambiente ou flag. Vazio para erros de validação.
+	Source string
+	Err    error
+}
+
+func (e *KeyError) Error() string {
+	if e.Source != "" {
+		return fmt.Sprintf("%s: %s: %v", e.Source, e.Key, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Key, e.Err)
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// option é uma chave de configuração, que pode vir do arquivo, de uma
+// variável de ambiente ou de uma flag
+type option struct {
+	key   string
+	usage string
+	set   func(c *Config, value string) error
+	get   func(c *Config) string
+	// isBool permite usar a flag sem valor (--headless)
+	isBool bool
+	// secret esconde o valor nos logs de mudança de configuração e não
+	// vira flag, para não aparecer na linha de comando (ps, /proc)
+	secret bool
+}
+
+// flagName é a chave com "." e "_" trocados por "-"
+func (o option) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(o.key)
+}
+
+func (o option) envName() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(o.key, ".", "_"))
+}
+
+var options = []option{
+	boolOption("headless", "executa o agente como daemon, sem interface gráfica",
+		func(c *Config) *bool { return &c.Headless }),
+	stringOption("log.level", "nível mínimo de log: debug, info, warn ou error",
+		func(c *Config) *string { return &c.LogLevel }),
+	stringOption("log.format", "formato do log: text ou json",
+		func(c *Config) *string { return &c.LogFormat }),
+	stringOption("log.path", "diretório dos arquivos de log",
+		func(c *Config) *string { return &c.LogPath }),
+	{
+		key:   "log.max_size_mb",
+		usage: "tamanho, em MiB, a partir do qual o arquivo de log é rotacionado",
+		set: func(c *Config, value string) error {
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("número inválido %q", value)
+			}
+			c.LogRotation.MaxSize = n << 20
+			return nil
+		},
+		get: func(c *Config) string { return strconv.FormatInt(c.LogRotation.MaxSize>>20, 10) },
+	},
+	boolOption("log.daily", "também rotaciona o arquivo de log na virada do dia",
+		func(c *Config) *bool { return &c.LogRotation.Daily }),
+	intOption("log.max_backups", "número máximo de arquivos de log antigos mantidos (0 mantém todos)",
+		func(c *Config) *int { return &c.LogRotation.MaxBackups }),
+	durationOption("log.max_age", "apaga os arquivos de log antigos mais velhos que a duração (0 mantém todos)",
+		func(c *Config) *time.Duration { return &c.LogRotation.MaxAge }),
+	boolOption("log.compress", "comprime com gzip os arquivos de log rotacionados",
+		func(c *Config) *bool { return &c.LogRotation.Compress }),
+	{
+		key:   "log.sinks",
+		usage: "destinos do log, separados por vírgula: file, syslog e journald",
+		set: func(c *Config, value string) error {
+			c.LogSinks = splitList(value)
+			return nil
+		},
+		get: func(c *Config) string { return strings.Join(c.LogSinks, ",") },
+	},
+	stringOption("log.syslog.addr", "destino do syslog: vazio (local), unix:///caminho, udp://host:porta ou tcp://host:porta",
+		func(c *Config) *string { return &c.LogSyslog.Addr }),
+	stringOption("log.syslog.facility", "facilidade das mensagens enviadas ao syslog",
+		func(c *Config) *string { return &c.LogSyslog.Facility }),
+	stringOption("data_path", "diretório dos dados do agente (métricas, fila, auditoria)",
+		func(c *Config) *string { return &c.DataPath }),
+	stringOption("api.addr", "endereço de escuta da API HTTP (vazio desativa)",
+		func(c *Config) *string { return &c.APIAddr }),
+	stringOption("usb.policy_path", "arquivo JSON com a política de dispositivos USB",
+		func(c *Config) *string { return &c.USBPolicyPath }),
+	durationOption("metrics.interval", "intervalo entre as amostras de métricas",
+		func(c *Config) *time.Duration { return &c.MetricsInterval }),
+	durationOption("metrics.retention.raw", "retenção em disco dos pontos brutos",
+		func(c *Config) *time.Duration { return &c.MetricsRetention.RawRetention }),
+	durationOption("metrics.retention.minute", "retenção em disco dos agregados por minuto",
+		func(c *Config) *time.Duration { return &c.MetricsRetention.MinuteRetention }),
+	durationOption("metrics.retention.hour", "retenção em disco dos agregados por hora",
+		func(c *Config) *time.Duration { return &c.MetricsRetention.HourRetention }),
+	intOption("metrics.top_processes", "número de processos com maior uso de CPU guardados a cada amostra (0 desativa)",
+		func(c *Config) *int { return &c.TopProcesses }),
+	durationOption("inventory.interval", "intervalo entre coletas completas do inventário",
+		func(c *Config) *time.Duration { return &c.InventoryInterval }),
+	{
+		key:   "collectors.disabled",
+		usage: "coletores de inventário desativados, separados por vírgula",
+		set: func(c *Config, value string) error {
+			c.DisabledCollectors = splitList(value)
+			return nil
+		},
+		get: func(c *Config) string { return strings.Join(c.DisabledCollectors, ",") },
+	},
+	{
+		key:   "collectors.timeouts",
+		usage: "timeout por coletor, no formato nome=duração separados por vírgula",
+		set: func(c *Config, value string) error {
+			timeouts := make(map[string]time.Duration)
+			for _, item := range splitList(value) {
+				name, v, ok := strings.Cut(item, "=")
+				if !ok {
+					return fmt.Errorf("item inválido %q (use nome=duração)", item)
+				}
+				d, err := time.ParseDuration(strings.TrimSpace(v))
+				if err != nil {
+					return fmt.Errorf("duração inválida %q para o coletor %s", v, name)
+				}
+				timeouts[strings.TrimSpace(name)] = d
+			}
+			c.CollectorTimeouts = timeouts
+			return nil
+		},
+		get: func(c *Config) string {
+			items := make([]string, 0, len(c.CollectorTimeouts))
+			for name, d := range c.CollectorTimeouts {
+				items = append(items, name+"="+d.String())
+			}
+			sort.Strings(items)
+			return strings.Join(items, ",")
+		},
+	},
+	intOption("collectors.disks.min_size_gb", "ignora os discos menores que o tamanho, em GB (0 inclui todos)",
+		func(c *Config) *int { return &c.Disks.MinSizeGB }),
+	boolOption("collectors.disks.removable", "inclui no inventário os discos removíveis",
+		func(c *Config) *bool { return &c.Disks.Removable }),
+	{
+		key:   "collectors.disks.exclude",
+		usage: "padrões do nome dos discos ignorados, separados por vírgula (ex.: loop*,sr*)",
+		set: func(c *Config, value string) error {
+			c.Disks.Exclude = splitList(value)
+			return nil
+		},
+		get: func(c *Config) string { return strings.Join(c.Disks.Exclude, ",") },
+	},
+	stringOption("server.url", "URL do servidor central de inventário",
+		func(c *Config) *string { return &c.ServerURL }),
+	secret(stringOption("server.enrollment_token", "token de registro no servidor central",
+		func(c *Config) *string { return &c.EnrollmentToken })),
+	durationOption("server.report_interval", "intervalo entre os envios ao servidor",
+		func(c *Config) *time.Duration { return &c.ReportInterval }),
+	{
+		key:   "server.spool_max_mb",
+		usage: "tamanho máximo, em MiB, da fila de relatórios pendentes",
+		set: func(c *Config, value string) error {
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("número inválido %q", value)
+			}
+			c.SpoolMaxBytes = n << 20
+			return nil
+		},
+		get: func(c *Config) string { return strconv.FormatInt(c.SpoolMaxBytes>>20, 10) },
+	},
+	stringOption("export.dir", "diretório das exportações",
+		func(c *Config) *string { return &c.ExportDir }),
+	stringOption("export.format", "formato das exportações: csv ou json",
+		func(c *Config) *string { return &c.ExportFormat }),
+}
+
+func stringOption(key, usage string, field func(*Config) *string) option {
+	return option{
+		key:   key,
+		usage: usage,
+		set: func(c *Config, value string) error {
+			*field(c) = value
+			return nil
+		},
+		get: func(c *Config) string { return *field(c) },
+	}
+}
+
+func boolOption(key, usage string, field func(*Config) *bool) option {
+	return option{
+		key:    key,
+		usage:  usage,
+		isBool: true,
+		set: func(c *Config, value string) error {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("valor booleano inválido %q", value)
+			}
+			*field(c) = b
+			return nil
+		},
+		get: func(c *Config) string { return strconv.FormatBool(*field(c)) },
+	}
+}
+
+func intOption(key, usage string, field func(*Config) *int) option {
+	return option{
+		key:   key,
+		usage: usage,
+		set: func(c *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("número inválido %q", value)
+			}
+			*field(c) = n
+			return nil
+		},
+		get: func(c *Config) string { return strconv.Itoa(*field(c)) },
+	}
+}
+
+func durationOption(key, usage string, field func(*Config) *time.Duration) option {
+	return option{
+		key:   key,
+		usage: usage,
+		set: func(c *Config, value string) error {
+			d, err := parseDuration(value)
+			if err != nil {
+				return err
+			}
+			*field(c) = d
+			return nil
+		},
+		get: func(c *Config) string { return field(c).String() },
+	}
+}
+
+func secret(o option) option {
+	o.secret = true
+	return o
+}
+
+// parseDuration aceita as durações do Go (30s, 5m, 1h30m) e também dias
+// (7d, 30d), comuns nas retenções
+func parseDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("duração inválida %q (ex.: 30s, 5m, 7d)", value)
+	}
+	return d, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func findOption(key string) (option, bool) {
+	for _, o := range options {
+		if o.key == key {
+			return o, true
+		}
+	}
+	return option{}, false
+}
+
+// knownSection indica se a chave é o início de alguma chave conhecida
+func knownSection(key string) bool {
+	for _, o := range options {
+		if strings.HasPrefix(o.key, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Load monta a configuração combinando, em ordem de prioridade crescente,
+// os valores padrão, o arquivo YAML, as variáveis de ambiente e as flags
+// em args. O arquivo é ConfigPath/config.yaml, ou o indicado por --config
+// ou FALCON_CONFIG. Retorna flag.ErrHelp se --help foi pedido.
+func Load(args []string) (*Config, error) {
+	c := New()
+	c.args = args
+
+	type flagValue struct {
+		opt   option
+		value string
+	}
+	var flagValues []flagValue
+	configFile := ""
+
+	fs := flag.NewFlagSet("falcon-agent", flag.ContinueOnError)
+	fs.StringVar(&configFile, "config", "", "arquivo de configuração YAML (padrão: config/"+ConfigFileName+")")
+	for _, o := range options {
+		if o.secret {
+			continue
+		}
+		o := o
+		record := func(value string) error {
+			flagValues = append(flagValues, flagValue{opt: o, value: value})
+			return nil
+		}
+		if o.isBool {
+			fs.BoolFunc(o.flagName(), o.usage, record)
+		} else {
+			fs.Func(o.flagName(), o.usage, record)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// O arquivo só é obrigatório quando indicado explicitamente
+	explicit := true
+	if configFile == "" {
+		configFile = os.Getenv(EnvPrefix + "CONFIG")
+	}
+	if configFile == "" {
+		configFile = filepath.Join(c.ConfigPath, ConfigFileName)
+		explicit = false
+	}
+	c.path = configFile
+	if err := c.loadFile(configFile); err != nil {
+		if explicit || !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	} else {
+		c.ConfigFile = configFile
+	}
+
+	for _, o := range options {
+		value, ok := os.LookupEnv(o.envName())
+		if !ok {
+			continue
+		}
+		if err := o.set(c, value); err != nil {
+			return nil, &KeyError{Key: o.key, Source: o.envName(), Err: err}
+		}
+	}
+
+	for _, fv := range flagValues {
+		if err := fv.opt.set(c, fv.value); err != nil {
+			return nil, &KeyError{Key: fv.opt.key, Source: "--" + fv.opt.flagName(), Err: err}
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reload monta novamente a configuração a partir das mesmas fontes usadas
+// em Load: o arquivo (que pode ter mudado), o ambiente e as flags
+func (c *Config) Reload() (*Config, error) {
+	return Load(c.args)
+}
+
+// WatchFiles retorna os arquivos cuja alteração deve recarregar a
+// configuração: o arquivo de configuração, mesmo que ainda não exista, e a
+// política USB
+func (c *Config) WatchFiles() []string {
+	var files []string
+	if c.path != "" {
+		files = append(files, c.path)
+	}
+	if c.USBPolicyPath != "" {
+		files = append(files, c.USBPolicyPath)
+	}
+	return files
+}
+
+// Change é a mudança no valor de uma chave entre duas configurações
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+func (ch Change) String() string {
+	return fmt.Sprintf("%s: %q → %q", ch.Key, ch.Old, ch.New)
+}
+
+// Diff lista as chaves com valores diferentes entre as duas configurações.
+// Valores sensíveis, como o token de registro, aparecem mascarados.
+func Diff(old, new *Config) []Change {
+	var changes []Change
+	for _, o := range options {
+		before, after := o.get(old), o.get(new)
+		if before == after {
+			continue
+		}
+		if o.secret {
+			before, after = mask(before), mask(after)
+		}
+		changes = append(changes, Change{Key: o.key, Old: before, New: after})
+	}
+	return changes
+}
+
+func mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "***"
+}
+
+// loadFile aplica os valores do arquivo YAML. Chaves desconhecidas são
+// rejeitadas, com a linha onde aparecem.
+func (c *Config) loadFile(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+	if len(root.Content) == 0 {
+		return nil
+	}
+	return c.applyNode(filename, "", root.Content[0])
+}
+
+func (c *Config) applyNode(filename, key string, node *yaml.Node) error {
+	source := fmt.Sprintf("%s:%d", filename, node.Line)
+
+	if o, ok := findOption(key); ok {
+		value, err := nodeValue(node)
+		if err == nil {
+			err = o.set(c, value)
+		}
+		if err != nil {
+			return &KeyError{Key: key, Source: source, Err: err}
+		}
+		return nil
+	}
+
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		if key == "" {
+			return fmt.Errorf("%s: o arquivo de configuração deve conter chaves e valores", source)
+		}
+		return &KeyError{Key: key, Source: source, Err: errors.New("esperado um grupo de chaves")}
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name := node.Content[i].Value
+		child := name
+		if key != "" {
+			child = key + "." + name
+		}
+		if _, ok := findOption(child); !ok && !knownSection(child) {
+			return &KeyError{
+				Key:    child,
+				Source: fmt.Sprintf("%s:%d", filename, node.Content[i].Line),
+				Err:    errors.New("chave desconhecida"),
+			}
+		}
+		if err := c.applyNode(filename, child, node.Content[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nodeValue converte o nó YAML no mesmo formato de texto aceito pelas
+// variáveis de ambiente e flags: listas separadas por vírgula e mapas como
+// nome=valor
+func nodeValue(node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return "", nil
+		}
+		return node.Value, nil
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return "", errors.New("a lista deve conter apenas valores simples")
+			}
+			items = append(items, item.Value)
+		}
+		return strings.Join(items, ","), nil
+	case yaml.MappingNode:
+		items := make([]string, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			if v.Kind != yaml.ScalarNode {
+				return "", fmt.Errorf("valor inválido para %s", k.Value)
+			}
+			items = append(items, k.Value+"="+v.Value)
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", errors.New("valor inválido")
+}
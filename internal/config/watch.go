@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay agrupa as várias escritas de um editor em uma única recarga
+const reloadDelay = 500 * time.Millisecond
+
+// Watch chama onChange quando algum dos arquivos é criado, alterado,
+// substituído ou removido, até o contexto ser cancelado. São observados os
+// diretórios, e não os arquivos, porque editores costumam salvar gravando
+// um arquivo novo e renomeando-o por cima do antigo. Diretórios que não
+// existem são ignorados.
+func Watch(ctx context.Context, files []string, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar observação de arquivos: %v", err)
+	}
+	defer watcher.Close()
+
+	names := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+		names[abs] = true
+
+		dir := filepath.Dir(abs)
+		if dirs[dir] {
+			continue
+		}
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("erro ao observar %s: %v", dir, err)
+		}
+		dirs[dir] = true
+	}
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	var timer *time.Timer
+	var fire <-chan time.Time
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !names[filepath.Clean(event.Name)] || event.Op == fsnotify.Chmod {
+				continue
+			}
+			if timer == nil {
+				timer = time.NewTimer(reloadDelay)
+			} else {
+				timer.Reset(reloadDelay)
+			}
+			fire = timer.C
+		case _, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+		case <-fire:
+			fire = nil
+			onChange()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
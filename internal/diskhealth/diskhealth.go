@@ -0,0 +1,76 @@
+package diskhealth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// Sample é a saúde de um disco em um instante
+type Sample struct {
+	Time time.Time `json:"time"`
+	model.DiskHealth
+}
+
+// Event avisa que um atributo de saúde de um disco piorou
+type Event struct {
+	Time   time.Time `json:"time"`
+	Serial string    `json:"serial"`
+	Device string    `json:"device"`
+	Model  string    `json:"model"`
+	// Attribute é o atributo que piorou, com o nome usado no JSON de
+	// model.DiskHealth (ex.: "reallocated_sectors")
+	Attribute string `json:"attribute"`
+	Old       any    `json:"old"`
+	New       any    `json:"new"`
+	// Summary é uma descrição legível do problema
+	Summary string `json:"summary"`
+}
+
+// wearThresholds são os níveis de desgaste do NVMe, em porcentagem, que
+// geram um aviso quando ultrapassados
+var wearThresholds = []int{80, 90, 100}
+
+// Degradations compara duas leituras do mesmo disco e retorna um evento
+// para cada atributo que piorou. A temperatura e as horas ligadas variam
+// normalmente e não geram eventos.
+func Degradations(old, new model.DiskHealth) []Event {
+	var events []Event
+	add := func(attribute string, oldValue, newValue any, format string, v ...any) {
+		events = append(events, Event{
+			Serial:    new.Serial,
+			Device:    new.Device,
+			Model:     new.Model,
+			Attribute: attribute,
+			Old:       oldValue,
+			New:       newValue,
+			Summary:   fmt.Sprintf("Disco %s (serial %s): ", new.Model, new.Serial) + fmt.Sprintf(format, v...),
+		})
+	}
+
+	if old.Passed && !new.Passed {
+		add("passed", old.Passed, new.Passed, "reprovado na avaliação SMART, substitua o disco")
+	}
+	counters := []struct {
+		attribute string
+		name      string
+		old, new  uint64
+	}{
+		{"reallocated_sectors", "setores realocados", old.ReallocatedSectors, new.ReallocatedSectors},
+		{"pending_sectors", "setores pendentes", old.PendingSectors, new.PendingSectors},
+		{"media_errors", "erros de mídia", old.MediaErrors, new.MediaErrors},
+	}
+	for _, c := range counters {
+		if c.new > c.old {
+			add(c.attribute, c.old, c.new, "%s aumentaram de %d para %d", c.name, c.old, c.new)
+		}
+	}
+	for _, threshold := range wearThresholds {
+		if old.PercentageUsed < threshold && new.PercentageUsed >= threshold {
+			add("percentage_used", old.PercentageUsed, new.PercentageUsed, "desgaste atingiu %d%%", new.PercentageUsed)
+			break
+		}
+	}
+	return events
+}
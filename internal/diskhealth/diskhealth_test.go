@@ -0,0 +1,118 @@
+package diskhealth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+func healthyDisk() model.DiskHealth {
+	return model.DiskHealth{
+		Device:         "sda",
+		Model:          "WDC WD10",
+		Serial:         "WD-123",
+		Passed:         true,
+		PowerOnHours:   12000,
+		TemperatureC:   36,
+		PercentageUsed: 75,
+	}
+}
+
+// attributes lista os atributos dos eventos, na ordem
+func attributes(events []Event) []string {
+	var names []string
+	for _, e := range events {
+		names = append(names, e.Attribute)
+	}
+	return names
+}
+
+func TestDegradations(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(d *model.DiskHealth)
+		want   []string
+	}{
+		{"sem mudança", func(d *model.DiskHealth) {}, nil},
+		{"temperatura e horas ligado", func(d *model.DiskHealth) { d.TemperatureC = 60; d.PowerOnHours++ }, nil},
+		{"avaliação reprovada", func(d *model.DiskHealth) { d.Passed = false }, []string{"passed"}},
+		{"setores realocados e pendentes", func(d *model.DiskHealth) {
+			d.ReallocatedSectors = 8
+			d.PendingSectors = 1
+		}, []string{"reallocated_sectors", "pending_sectors"}},
+		{"erros de mídia", func(d *model.DiskHealth) { d.MediaErrors = 2 }, []string{"media_errors"}},
+		{"desgaste abaixo do limite", func(d *model.DiskHealth) { d.PercentageUsed = 79 }, nil},
+		{"desgaste passa de dois limites", func(d *model.DiskHealth) { d.PercentageUsed = 95 }, []string{"percentage_used"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disk := healthyDisk()
+			tt.modify(&disk)
+			got := attributes(Degradations(healthyDisk(), disk))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Degradations = %v, quer %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Degradations = %v, quer %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDegradationsIgnoresImprovements(t *testing.T) {
+	old := healthyDisk()
+	old.Passed = false
+	old.PendingSectors = 4
+	new := healthyDisk()
+	if events := Degradations(old, new); len(events) != 0 {
+		t.Errorf("melhora reportada como piora: %+v", events)
+	}
+}
+
+func TestStoreRecord(t *testing.T) {
+	store, err := OpenStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	disk := healthyDisk()
+	record := func(at time.Time, disks ...model.DiskHealth) []Event {
+		t.Helper()
+		events, err := store.Record(at, disks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return events
+	}
+
+	record(now, disk, model.DiskHealth{Device: "sdb"})
+	// Sem piora, uma nova leitura só é gravada depois de SampleInterval
+	record(now.Add(time.Minute), disk)
+	record(now.Add(SampleInterval), disk)
+
+	disk.ReallocatedSectors = 3
+	events := record(now.Add(SampleInterval+time.Minute), disk)
+	if len(events) != 1 || events[0].Attribute != "reallocated_sectors" || events[0].Serial != "WD-123" {
+		t.Fatalf("eventos = %+v", events)
+	}
+
+	history, err := store.History("WD-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 {
+		t.Errorf("histórico com %d leituras, quer 3", len(history))
+	}
+	saved, err := store.Events(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].Attribute != "reallocated_sectors" {
+		t.Errorf("eventos gravados = %+v", saved)
+	}
+}
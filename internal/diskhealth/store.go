@@ -0,0 +1,197 @@
+package diskhealth
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// SampleInterval é o intervalo mínimo entre as leituras gravadas de um
+// mesmo disco; leituras que geram eventos são sempre gravadas
+const SampleInterval = time.Hour
+
+// eventsFile é o arquivo, no diretório do Store, com os eventos de piora
+const eventsFile = "events.log"
+
+// Store guarda em disco o histórico de saúde de cada disco, em um arquivo
+// por serial com uma leitura JSON por linha, e os eventos de piora
+type Store struct {
+	mu     sync.Mutex
+	dir    string
+	events *os.File
+	// last é a última leitura gravada de cada serial
+	last map[string]Sample
+}
+
+// OpenStore abre (ou cria) o histórico de saúde dos discos em dir
+func OpenStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório do histórico de saúde dos discos: %v", err)
+	}
+
+	events, err := os.OpenFile(filepath.Join(dir, eventsFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir eventos de saúde dos discos: %v", err)
+	}
+	return &Store{dir: dir, events: events, last: make(map[string]Sample)}, nil
+}
+
+// Record compara as leituras com as últimas gravadas de cada disco e
+// retorna os eventos de piora. É gravada uma leitura por hora de cada
+// disco, além das que pioraram. Discos sem serial são ignorados, pois não
+// há como acompanhá-los.
+func (s *Store) Record(now time.Time, disks []model.DiskHealth) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []Event
+	for _, disk := range disks {
+		if disk.Serial == "" {
+			continue
+		}
+
+		previous, ok := s.last[disk.Serial]
+		if !ok {
+			previous, ok = s.readLast(disk.Serial)
+		}
+		var degraded []Event
+		if ok {
+			degraded = Degradations(previous.DiskHealth, disk)
+		}
+		if ok && len(degraded) == 0 && now.Sub(previous.Time) < SampleInterval {
+			continue
+		}
+
+		sample := Sample{Time: now, DiskHealth: disk}
+		if err := appendJSON(s.sampleFile(disk.Serial), sample); err != nil {
+			return events, err
+		}
+		s.last[disk.Serial] = sample
+
+		for _, event := range degraded {
+			event.Time = now
+			data, err := json.Marshal(event)
+			if err != nil {
+				return events, err
+			}
+			if _, err := s.events.Write(append(data, '\n')); err != nil {
+				return events, err
+			}
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// History retorna as leituras gravadas de um disco, da mais antiga para a
+// mais recente
+func (s *Store) History(serial string) ([]Sample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var samples []Sample
+	err := readLines(s.sampleFile(serial), func(line []byte) {
+		var sample Sample
+		if json.Unmarshal(line, &sample) == nil {
+			samples = append(samples, sample)
+		}
+	})
+	return samples, err
+}
+
+// Events retorna os últimos eventos de piora, do mais antigo para o mais
+// recente. Com limit <= 0 retorna todos.
+func (s *Store) Events(limit int) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []Event
+	err := readLines(filepath.Join(s.dir, eventsFile), func(line []byte) {
+		var event Event
+		if json.Unmarshal(line, &event) != nil {
+			return
+		}
+		events = append(events, event)
+		if limit > 0 && len(events) > limit {
+			events = events[1:]
+		}
+	})
+	return events, err
+}
+
+// Close fecha o arquivo de eventos
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.events.Close()
+}
+
+// readLast lê a última leitura gravada do disco, usada como referência
+// depois que o agente reinicia
+func (s *Store) readLast(serial string) (Sample, bool) {
+	var last Sample
+	found := false
+	readLines(s.sampleFile(serial), func(line []byte) {
+		var sample Sample
+		if json.Unmarshal(line, &sample) == nil {
+			last, found = sample, true
+		}
+	})
+	return last, found
+}
+
+// sampleFile retorna o arquivo do histórico do disco (disk-<serial>.log).
+// Caracteres que não podem aparecer em nomes de arquivo viram "_".
+func (s *Store) sampleFile(serial string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		}
+		return '_'
+	}, serial)
+	return filepath.Join(s.dir, "disk-"+name+".log")
+}
+
+func appendJSON(filename string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// readLines chama fn para cada linha do arquivo. Um arquivo inexistente é
+// tratado como vazio.
+func readLines(filename string, fn func(line []byte)) error {
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fn(scanner.Bytes())
+	}
+	return scanner.Err()
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/dev/falcon-agent/internal/metrics"
@@ -18,7 +19,7 @@ type Exporter interface {
 type CSVExporter struct{}
 
 func (e *CSVExporter) Export(data interface{}, filename string) error {
-	metrics, ok := data.(*metrics.SystemMetrics)
+	systemMetrics, ok := data.(*metrics.SystemMetrics)
 	if !ok {
 		return fmt.Errorf("dados inválidos para exportação CSV")
 	}
@@ -33,21 +34,51 @@ func (e *CSVExporter) Export(data interface{}, filename string) error {
 	defer writer.Flush()
 
 	// Escreve o cabeçalho
-	header := []string{"Timestamp", "CPU Usage (%)", "Memory Usage (%)"}
+	header := []string{"Timestamp", "CPU Usage (%)", "Memory Usage (%)", "Swap Usage (%)", "Load 1m", "Load 5m", "Load 15m",
+		"Disk Read (B/s)", "Disk Write (B/s)", "Network Received (B/s)", "Network Sent (B/s)"}
+	series := [][]metrics.MetricPoint{
+		systemMetrics.CPUUsage.GetPoints(),
+		systemMetrics.MemoryUsage.GetPoints(),
+		systemMetrics.SwapUsage.GetPoints(),
+		systemMetrics.Load1.GetPoints(),
+		systemMetrics.Load5.GetPoints(),
+		systemMetrics.Load15.GetPoints(),
+		systemMetrics.DiskReadRate.GetPoints(),
+		systemMetrics.DiskWriteRate.GetPoints(),
+		systemMetrics.NetRecvRate.GetPoints(),
+		systemMetrics.NetSentRate.GetPoints(),
+	}
+	for i, core := range systemMetrics.CPUCoreUsage {
+		header = append(header, fmt.Sprintf("CPU %d Usage (%%)", i))
+		series = append(series, core.GetPoints())
+	}
 	if err := writer.Write(header); err != nil {
 		return err
 	}
 
-	cpuPoints := metrics.CPUUsage.GetPoints()
-	memPoints := metrics.MemoryUsage.GetPoints()
+	// As séries de uma amostra têm o mesmo horário; uma leitura que falhou
+	// deixa a célula da série vazia naquela linha
+	values := make(map[int64][]string)
+	var timestamps []time.Time
+	for i, points := range series {
+		for _, p := range points {
+			key := p.Timestamp.UnixNano()
+			row, ok := values[key]
+			if !ok {
+				row = make([]string, len(series))
+				values[key] = row
+				timestamps = append(timestamps, p.Timestamp)
+			}
+			row[i] = fmt.Sprintf("%.2f", p.Value)
+		}
+	}
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i].Before(timestamps[j])
+	})
 
 	// Escreve os dados
-	for i := 0; i < len(cpuPoints); i++ {
-		row := []string{
-			cpuPoints[i].Timestamp.Format(time.RFC3339),
-			fmt.Sprintf("%.2f", cpuPoints[i].Value),
-			fmt.Sprintf("%.2f", memPoints[i].Value),
-		}
+	for _, ts := range timestamps {
+		row := append([]string{ts.Format(time.RFC3339)}, values[ts.UnixNano()]...)
 		if err := writer.Write(row); err != nil {
 			return err
 		}
@@ -70,9 +101,10 @@ func (e *JSONExporter) Export(data interface{}, filename string) error {
 	return encoder.Encode(data)
 }
 
-func ExportData(data interface{}, format string, baseDir string) error {
+// ExportData grava os dados no formato informado e retorna o arquivo criado
+func ExportData(data interface{}, format string, baseDir string) (string, error) {
 	if err := os.MkdirAll(baseDir, 0755); err != nil {
-		return err
+		return "", err
 	}
 
 	timestamp := time.Now().Format("2006-01-02_15-04-05")
@@ -87,8 +119,8 @@ func ExportData(data interface{}, format string, baseDir string) error {
 		exporter = &JSONExporter{}
 		filename = filepath.Join(baseDir, fmt.Sprintf("metrics_%s.json", timestamp))
 	default:
-		return fmt.Errorf("formato de exportação não suportado: %s", format)
+		return "", fmt.Errorf("formato de exportação não suportado: %s", format)
 	}
 
-	return exporter.Export(data, filename)
+	return filename, exporter.Export(data, filename)
 }
@@ -0,0 +1,302 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// inventoryRow é uma linha do inventário exportado em CSV: a seção (ex.:
+// "Rede"), o item dentro da seção (ex.: a interface "eth0"), o campo e o valor
+type inventoryRow struct {
+	Section string
+	Item    string
+	Field   string
+	Value   string
+}
+
+// inventorySections monta as linhas de cada seção do inventário
+var inventorySections = []func(info *model.MachineInfo) []inventoryRow{
+	systemRows,
+	processorRows,
+	biosRows,
+	memoryRows,
+	diskRows,
+	diskHealthRows,
+	usbRows,
+	networkRows,
+	displayRows,
+	pciRows,
+	softwareRows,
+}
+
+func systemRows(info *model.MachineInfo) []inventoryRow {
+	osInfo := info.OSDetails
+	bootTime := ""
+	if !osInfo.BootTime.IsZero() {
+		bootTime = osInfo.BootTime.Format(time.RFC3339)
+	}
+	return []inventoryRow{
+		{"Sistema", "", "Sistema Operacional", info.OS},
+		{"Sistema", "", "Distribuição", osInfo.Name},
+		{"Sistema", "", "Versão", osInfo.Version},
+		{"Sistema", "", "ID da Distribuição", osInfo.ID},
+		{"Sistema", "", "ID da Versão", osInfo.VersionID},
+		{"Sistema", "", "Kernel", osInfo.Kernel},
+		{"Sistema", "", "Arquitetura", osInfo.Arch},
+		{"Sistema", "", "Ligado desde", bootTime},
+		{"Sistema", "", "Tempo ligado (s)", fmt.Sprint(osInfo.UptimeSeconds)},
+		{"Sistema", "", "Fuso horário", osInfo.Timezone},
+		{"Sistema", "", "Idioma", osInfo.Locale},
+		{"Sistema", "", "Virtualização", osInfo.Virtualization},
+		{"Sistema", "", "Contêiner", osInfo.Container},
+		{"Sistema", "", "Secure Boot", osInfo.SecureBoot},
+		{"Sistema", "", "Hostname", info.Hostname},
+		{"Sistema", "", "Serial Number", info.SerialNumber},
+		{"Sistema", "", "Serial da Placa-mãe", info.MotherboardSN},
+	}
+}
+
+func processorRows(info *model.MachineInfo) []inventoryRow {
+	return []inventoryRow{
+		{"Processador", "", "Modelo", info.Processor.Model},
+		{"Processador", "", "Núcleos", fmt.Sprint(info.Processor.Cores)},
+		{"Processador", "", "Threads", fmt.Sprint(info.Processor.Threads)},
+		{"Processador", "", "Frequência (GHz)", fmt.Sprintf("%.2f", info.Processor.FrequencyGHz)},
+	}
+}
+
+func biosRows(info *model.MachineInfo) []inventoryRow {
+	return []inventoryRow{
+		{"BIOS", "", "Fabricante", info.BIOS.Vendor},
+		{"BIOS", "", "Versão", info.BIOS.Version},
+		{"BIOS", "", "Data de Lançamento", info.BIOS.ReleaseDate},
+	}
+}
+
+func memoryRows(info *model.MachineInfo) []inventoryRow {
+	var rows []inventoryRow
+	for _, mem := range info.Memory {
+		rows = append(rows,
+			inventoryRow{"Memória", mem.Slot, "Capacidade (MB)", fmt.Sprint(mem.SizeMB)},
+			inventoryRow{"Memória", mem.Slot, "Fabricante", mem.Manufacturer},
+			inventoryRow{"Memória", mem.Slot, "Serial", mem.SerialNumber},
+		)
+	}
+	return rows
+}
+
+func diskRows(info *model.MachineInfo) []inventoryRow {
+	var rows []inventoryRow
+	for _, hd := range info.HDs {
+		rows = append(rows,
+			inventoryRow{"Armazenamento", hd.Name, "Modelo", hd.Model},
+			inventoryRow{"Armazenamento", hd.Name, "Serial", hd.Serial},
+			inventoryRow{"Armazenamento", hd.Name, "Capacidade (GB)", fmt.Sprint(hd.SizeGB)},
+			inventoryRow{"Armazenamento", hd.Name, "Controladora", hd.Controller},
+			inventoryRow{"Armazenamento", hd.Name, "Removível", fmt.Sprint(hd.Removable)},
+			inventoryRow{"Armazenamento", hd.Name, "Rotacional", fmt.Sprint(hd.Rotational)},
+			inventoryRow{"Armazenamento", hd.Name, "NVMe", fmt.Sprint(hd.NVMe)},
+		)
+		for _, part := range hd.Partitions {
+			rows = append(rows,
+				inventoryRow{"Partições", part.Name, "Disco", hd.Name},
+				inventoryRow{"Partições", part.Name, "Sistema de arquivos", part.Filesystem},
+				inventoryRow{"Partições", part.Name, "Rótulo", part.Label},
+				inventoryRow{"Partições", part.Name, "Ponto de montagem", part.MountPoint},
+				inventoryRow{"Partições", part.Name, "Tamanho (GB)", gigabytes(part.SizeBytes)},
+				inventoryRow{"Partições", part.Name, "Usado (GB)", gigabytes(part.UsedBytes)},
+				inventoryRow{"Partições", part.Name, "Livre (GB)", gigabytes(part.FreeBytes)},
+			)
+		}
+	}
+	return rows
+}
+
+// gigabytes formata bytes em GB com uma casa decimal
+func gigabytes(b uint64) string {
+	return fmt.Sprintf("%.1f", float64(b)/(1024*1024*1024))
+}
+
+func diskHealthRows(info *model.MachineInfo) []inventoryRow {
+	var rows []inventoryRow
+	for _, h := range info.DiskHealth {
+		status := "OK"
+		if !h.Passed {
+			status = "FALHA"
+		}
+		rows = append(rows,
+			inventoryRow{"Saúde dos discos", h.Serial, "Dispositivo", h.Device},
+			inventoryRow{"Saúde dos discos", h.Serial, "Avaliação SMART", status},
+			inventoryRow{"Saúde dos discos", h.Serial, "Temperatura (°C)", fmt.Sprint(h.TemperatureC)},
+			inventoryRow{"Saúde dos discos", h.Serial, "Horas ligado", fmt.Sprint(h.PowerOnHours)},
+			inventoryRow{"Saúde dos discos", h.Serial, "Setores realocados", fmt.Sprint(h.ReallocatedSectors)},
+			inventoryRow{"Saúde dos discos", h.Serial, "Setores pendentes", fmt.Sprint(h.PendingSectors)},
+			inventoryRow{"Saúde dos discos", h.Serial, "Erros de mídia", fmt.Sprint(h.MediaErrors)},
+			inventoryRow{"Saúde dos discos", h.Serial, "Desgaste (%)", fmt.Sprint(h.PercentageUsed)},
+		)
+	}
+	return rows
+}
+
+func usbRows(info *model.MachineInfo) []inventoryRow {
+	var rows []inventoryRow
+	for _, dev := range info.USBDevices {
+		rows = append(rows,
+			inventoryRow{"USB", dev.Port, "Nome", dev.Name},
+			inventoryRow{"USB", dev.Port, "Vendor ID", dev.VendorID},
+			inventoryRow{"USB", dev.Port, "Product ID", dev.ProductID},
+			inventoryRow{"USB", dev.Port, "Serial", dev.Serial},
+		)
+	}
+	return rows
+}
+
+func networkRows(info *model.MachineInfo) []inventoryRow {
+	var rows []inventoryRow
+	for _, iface := range info.Network.Interfaces {
+		state := "down"
+		if iface.Up {
+			state = "up"
+		}
+		rows = append(rows,
+			inventoryRow{"Rede", iface.Name, "MAC", iface.MAC},
+			inventoryRow{"Rede", iface.Name, "IPv4", strings.Join(iface.IPv4, " ")},
+			inventoryRow{"Rede", iface.Name, "IPv6", strings.Join(iface.IPv6, " ")},
+			inventoryRow{"Rede", iface.Name, "Gateway", strings.Join(iface.Gateways, " ")},
+			inventoryRow{"Rede", iface.Name, "Velocidade (Mbps)", fmt.Sprint(iface.SpeedMbps)},
+			inventoryRow{"Rede", iface.Name, "MTU", fmt.Sprint(iface.MTU)},
+			inventoryRow{"Rede", iface.Name, "Estado", state},
+			inventoryRow{"Rede", iface.Name, "Driver", iface.Driver},
+			inventoryRow{"Rede", iface.Name, "Tipo", iface.Type},
+		)
+	}
+	rows = append(rows, inventoryRow{"Rede", "", "Servidores DNS", strings.Join(info.Network.DNSServers, " ")})
+	return rows
+}
+
+func displayRows(info *model.MachineInfo) []inventoryRow {
+	var rows []inventoryRow
+	for _, gpu := range info.GPUs {
+		rows = append(rows,
+			inventoryRow{"Vídeo", gpu.PCIAddress, "Fabricante", gpu.Vendor},
+			inventoryRow{"Vídeo", gpu.PCIAddress, "Modelo", gpu.Model},
+			inventoryRow{"Vídeo", gpu.PCIAddress, "Driver", gpu.Driver},
+			inventoryRow{"Vídeo", gpu.PCIAddress, "VRAM (MB)", fmt.Sprint(gpu.VRAMMB)},
+		)
+	}
+	for _, monitor := range info.Monitors {
+		rows = append(rows,
+			inventoryRow{"Monitores", monitor.Connector, "Fabricante", monitor.Manufacturer},
+			inventoryRow{"Monitores", monitor.Connector, "Modelo", monitor.Model},
+			inventoryRow{"Monitores", monitor.Connector, "Serial", monitor.Serial},
+			inventoryRow{"Monitores", monitor.Connector, "Resolução", fmt.Sprintf("%dx%d", monitor.Width, monitor.Height)},
+		)
+	}
+	return rows
+}
+
+func pciRows(info *model.MachineInfo) []inventoryRow {
+	var rows []inventoryRow
+	for _, dev := range info.PCIDevices {
+		rows = append(rows,
+			inventoryRow{"PCI", dev.Address, "Classe", withID(dev.Class, dev.ClassID)},
+			inventoryRow{"PCI", dev.Address, "Fabricante", withID(dev.Vendor, dev.VendorID)},
+			inventoryRow{"PCI", dev.Address, "Produto", withID(dev.Product, dev.ProductID)},
+			inventoryRow{"PCI", dev.Address, "Subsistema", dev.Subsystem},
+			inventoryRow{"PCI", dev.Address, "Driver", dev.Driver},
+		)
+	}
+	return rows
+}
+
+func softwareRows(info *model.MachineInfo) []inventoryRow {
+	var rows []inventoryRow
+	for _, pkg := range info.Software {
+		installed := ""
+		if !pkg.InstallDate.IsZero() {
+			installed = pkg.InstallDate.Format(time.RFC3339)
+		}
+		item := pkg.Source + ":" + pkg.Name
+		rows = append(rows,
+			inventoryRow{"Software", item, "Versão", pkg.Version},
+			inventoryRow{"Software", item, "Arquitetura", pkg.Arch},
+			inventoryRow{"Software", item, "Fornecedor", pkg.Vendor},
+			inventoryRow{"Software", item, "Instalado em", installed},
+		)
+	}
+	return rows
+}
+
+// withID junta o nome e o ID numérico ("Intel Corporation (8086)"), ou
+// apenas o ID quando o nome é desconhecido
+func withID(name, id string) string {
+	if name == "" {
+		return id
+	}
+	return fmt.Sprintf("%s (%s)", name, id)
+}
+
+// InventoryCSVExporter grava o inventário em CSV, uma linha por campo
+type InventoryCSVExporter struct{}
+
+func (e *InventoryCSVExporter) Export(data interface{}, filename string) error {
+	info, ok := data.(*model.MachineInfo)
+	if !ok {
+		return fmt.Errorf("dados inválidos para exportação do inventário")
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"Seção", "Item", "Campo", "Valor"}); err != nil {
+		return err
+	}
+	for _, section := range inventorySections {
+		for _, row := range section(info) {
+			if err := writer.Write([]string{row.Section, row.Item, row.Field, row.Value}); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportInventory grava o inventário no formato informado e retorna o
+// arquivo criado. Em JSON o inventário é gravado completo, como enviado
+// ao servidor central.
+func ExportInventory(info *model.MachineInfo, format string, baseDir string) (string, error) {
+	if info == nil {
+		return "", fmt.Errorf("inventário ainda não coletado")
+	}
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return "", err
+	}
+
+	timestamp := time.Now().Format("2006-01-02_15-04-05")
+	var exporter Exporter
+	var filename string
+
+	switch format {
+	case "csv":
+		exporter = &InventoryCSVExporter{}
+		filename = filepath.Join(baseDir, fmt.Sprintf("inventory_%s.csv", timestamp))
+	case "json":
+		exporter = &JSONExporter{}
+		filename = filepath.Join(baseDir, fmt.Sprintf("inventory_%s.json", timestamp))
+	default:
+		return "", fmt.Errorf("formato de exportação não suportado: %s", format)
+	}
+
+	return filename, exporter.Export(info, filename)
+}
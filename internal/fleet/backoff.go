@@ -0,0 +1,39 @@
+package fleet
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff calcula as esperas entre tentativas com crescimento exponencial
+// e jitter, para que agentes que perderam o servidor ao mesmo tempo não
+// voltem todos juntos
+type Backoff struct {
+	Initial     time.Duration
+	Max         time.Duration
+	MaxAttempts int
+}
+
+// DefaultBackoff retorna a política padrão: 1s, 2s, 4s... até 1 minuto,
+// em no máximo 6 tentativas
+func DefaultBackoff() Backoff {
+	return Backoff{
+		Initial:     time.Second,
+		Max:         time.Minute,
+		MaxAttempts: 6,
+	}
+}
+
+// Delay retorna a espera antes da tentativa seguinte à tentativa attempt
+// (começando em 0), entre metade e o valor cheio do intervalo exponencial
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Initial
+	for i := 0; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,293 @@
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// Tipos de relatório enviados ao servidor
+const (
+	KindInventory = "inventory"
+	KindMetrics   = "metrics"
+	KindUSBEvent  = "usb_event"
+	// KindDiskHealthEvent avisa que um atributo de saúde de um disco piorou
+	KindDiskHealthEvent = "disk_health_event"
+)
+
+var (
+	// ErrUnauthorized indica que o servidor recusou as credenciais do agente
+	ErrUnauthorized = errors.New("agente não autorizado pelo servidor")
+	// ErrRejected indica que o servidor recusou a requisição em si (4xx) e
+	// que não adianta enviá-la novamente
+	ErrRejected = errors.New("requisição recusada pelo servidor")
+)
+
+// Report é o envelope de todos os dados enviados ao servidor
+type Report struct {
+	Kind      string          `json:"kind"`
+	MachineID string          `json:"machine_id"`
+	Time      time.Time       `json:"time"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewReport serializa os dados em um relatório do tipo informado
+func NewReport(kind, machineID string, data any) (Report, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Report{}, err
+	}
+	return Report{Kind: kind, MachineID: machineID, Time: time.Now(), Data: raw}, nil
+}
+
+// Enrollment é o registro do agente no servidor, persistido em disco. O
+// MachineID continua salvo quando o servidor recusa o token, para que o
+// novo registro use o mesmo ID.
+type Enrollment struct {
+	MachineID  string    `json:"machine_id"`
+	AgentToken string    `json:"agent_token"`
+	EnrolledAt time.Time `json:"enrolled_at"`
+}
+
+// enrollRequest é o corpo de POST /v1/agents/enroll
+type enrollRequest struct {
+	MachineID         string `json:"machine_id"`
+	EnrollmentToken   string `json:"enrollment_token"`
+	Hostname          string `json:"hostname"`
+	SerialNumber      string `json:"serial_number"`
+	MotherboardSerial string `json:"motherboard_serial"`
+}
+
+// enrollResponse é a resposta do servidor ao registro
+type enrollResponse struct {
+	AgentToken string `json:"agent_token"`
+}
+
+// ClientConfig reúne os parâmetros do cliente do servidor central
+type ClientConfig struct {
+	ServerURL       string
+	EnrollmentToken string
+	// StatePath é o arquivo onde o registro do agente é guardado
+	StatePath string
+	Backoff   Backoff
+	// HTTPClient permite substituir o cliente HTTP (ex.: em testes)
+	HTTPClient *http.Client
+}
+
+// Client registra o agente e envia relatórios ao servidor central
+type Client struct {
+	config ClientConfig
+	http   *http.Client
+
+	mu         sync.Mutex
+	enrollment *Enrollment
+	// machineID é o ID da máquina no servidor: o salvo no registro ou,
+	// antes do primeiro registro, o calculado uma única vez
+	machineID string
+}
+
+// NewClient cria um cliente, carregando o registro salvo se existir
+func NewClient(cfg ClientConfig) (*Client, error) {
+	u, err := url.Parse(cfg.ServerURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("URL do servidor inválida: %q", cfg.ServerURL)
+	}
+	if cfg.Backoff.MaxAttempts <= 0 {
+		cfg.Backoff = DefaultBackoff()
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	c := &Client{config: cfg, http: httpClient}
+	if data, err := os.ReadFile(cfg.StatePath); err == nil {
+		enrollment := &Enrollment{}
+		if json.Unmarshal(data, enrollment) == nil {
+			c.machineID = enrollment.MachineID
+			if enrollment.AgentToken != "" {
+				c.enrollment = enrollment
+			}
+		}
+	}
+	return c, nil
+}
+
+// MachineID retorna o ID da máquina no servidor. Na primeira chamada sem
+// registro salvo ele é derivado do inventário; depois é sempre o mesmo,
+// mesmo que os seriais mudem ou deixem de ser lidos. Retorna "" se o ID
+// ainda não é conhecido e info é nil.
+func (c *Client) MachineID(info *model.MachineInfo) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.machineID == "" && info != nil {
+		c.machineID = MachineID(info)
+	}
+	return c.machineID
+}
+
+// Enrolled indica se o agente já está registrado no servidor
+func (c *Client) Enrolled() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.enrollment != nil
+}
+
+// Enroll registra a máquina no servidor usando o token de registro e
+// guarda o token do agente devolvido pelo servidor
+func (c *Client) Enroll(ctx context.Context, info *model.MachineInfo) error {
+	machineID := c.MachineID(info)
+	body, err := json.Marshal(enrollRequest{
+		MachineID:         machineID,
+		EnrollmentToken:   c.config.EnrollmentToken,
+		Hostname:          info.Hostname,
+		SerialNumber:      info.SerialNumber,
+		MotherboardSerial: info.MotherboardSN,
+	})
+	if err != nil {
+		return err
+	}
+
+	var resp enrollResponse
+	if err := c.postWithRetry(ctx, "/v1/agents/enroll", "", body, &resp); err != nil {
+		return fmt.Errorf("erro ao registrar o agente: %v", err)
+	}
+	if resp.AgentToken == "" {
+		return fmt.Errorf("erro ao registrar o agente: servidor não retornou o token do agente")
+	}
+
+	enrollment := &Enrollment{
+		MachineID:  machineID,
+		AgentToken: resp.AgentToken,
+		EnrolledAt: time.Now(),
+	}
+	if err := saveEnrollment(c.config.StatePath, enrollment); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.enrollment = enrollment
+	c.mu.Unlock()
+	return nil
+}
+
+// Send envia um relatório ao servidor, com novas tentativas em caso de
+// falha de rede ou erro do servidor. Retorna ErrUnauthorized se o token do
+// agente foi recusado; nesse caso o token é descartado.
+func (c *Client) Send(ctx context.Context, report Report) error {
+	c.mu.Lock()
+	enrollment := c.enrollment
+	c.mu.Unlock()
+	if enrollment == nil {
+		return ErrUnauthorized
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/v1/agents/%s/reports", url.PathEscape(enrollment.MachineID))
+	err = c.postWithRetry(ctx, path, enrollment.AgentToken, body, nil)
+	if errors.Is(err, ErrUnauthorized) {
+		c.mu.Lock()
+		c.enrollment = nil
+		c.mu.Unlock()
+		saveEnrollment(c.config.StatePath, &Enrollment{MachineID: enrollment.MachineID})
+	}
+	return err
+}
+
+// postWithRetry faz o POST tentando novamente, com backoff exponencial,
+// enquanto o erro for temporário (rede, 429 ou 5xx)
+func (c *Client) postWithRetry(ctx context.Context, path, token string, body []byte, out any) error {
+	var lastErr error
+	for attempt := 0; attempt < c.config.Backoff.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(c.config.Backoff.Delay(attempt - 1)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		retry, err := c.post(ctx, path, token, body, out)
+		if err == nil {
+			return nil
+		}
+		if !retry {
+			return err
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("%v (após %d tentativas)", lastErr, c.config.Backoff.MaxAttempts)
+}
+
+// post faz uma única requisição e indica se vale tentar novamente
+func (c *Client) post(ctx context.Context, path, token string, body []byte, out any) (bool, error) {
+	endpoint, err := url.JoinPath(c.config.ServerURL, path)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return false, ErrUnauthorized
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("servidor respondeu %s", resp.Status)
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return false, fmt.Errorf("%w: %s: %s", ErrRejected, resp.Status, bytes.TrimSpace(msg))
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return false, fmt.Errorf("resposta inválida do servidor: %v", err)
+		}
+	}
+	return false, nil
+}
+
+// saveEnrollment grava o registro de forma atômica e legível apenas pelo agente
+func saveEnrollment(filename string, enrollment *Enrollment) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(enrollment, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("erro ao salvar registro do agente: %v", err)
+	}
+	return os.Rename(tmp, filename)
+}
@@ -0,0 +1,224 @@
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// fakeServer imita o servidor central: registra os agentes e guarda os
+// relatórios recebidos. status, quando definido, escolhe a resposta de
+// cada POST de relatório.
+type fakeServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	enrolls  []enrollRequest
+	reports  []Report
+	attempts int
+	status   func(attempt int) int
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	s := &fakeServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/agents/enroll", func(w http.ResponseWriter, r *http.Request) {
+		var req enrollRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.enrolls = append(s.enrolls, req)
+		s.mu.Unlock()
+		json.NewEncoder(w).Encode(enrollResponse{AgentToken: "token-" + req.MachineID})
+	})
+	mux.HandleFunc("POST /v1/agents/{id}/reports", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.attempts++
+		if s.status != nil {
+			if code := s.status(s.attempts); code != http.StatusOK {
+				w.WriteHeader(code)
+				return
+			}
+		}
+		if r.Header.Get("Authorization") != "Bearer token-"+r.PathValue("id") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var report Report
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.reports = append(s.reports, report)
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeServer) setStatus(status func(attempt int) int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+	s.attempts = 0
+}
+
+func (s *fakeServer) received() []Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Report(nil), s.reports...)
+}
+
+// testBackoff repete rapidamente para os testes não dependerem do relógio
+func testBackoff(attempts int) Backoff {
+	return Backoff{Initial: time.Millisecond, Max: time.Millisecond, MaxAttempts: attempts}
+}
+
+func newTestClient(t *testing.T, serverURL, statePath string) *Client {
+	t.Helper()
+	client, err := NewClient(ClientConfig{
+		ServerURL:       serverURL,
+		EnrollmentToken: "registro",
+		StatePath:       statePath,
+		Backoff:         testBackoff(3),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func testMachine() *model.MachineInfo {
+	return &model.MachineInfo{Hostname: "estacao-01", SerialNumber: "ABC123", MotherboardSN: "MB456"}
+}
+
+func TestEnrollPersistsMachineID(t *testing.T) {
+	server := newFakeServer(t)
+	state := filepath.Join(t.TempDir(), "enrollment.json")
+	client := newTestClient(t, server.URL, state)
+
+	info := testMachine()
+	if err := client.Enroll(context.Background(), info); err != nil {
+		t.Fatal(err)
+	}
+	if !client.Enrolled() {
+		t.Fatal("cliente não ficou registrado")
+	}
+	want := MachineID(info)
+	if len(server.enrolls) != 1 || server.enrolls[0].MachineID != want || server.enrolls[0].EnrollmentToken != "registro" {
+		t.Fatalf("registro recebido = %+v", server.enrolls)
+	}
+
+	// Após reiniciar, o ID salvo vale mesmo que os seriais mudem
+	reopened := newTestClient(t, server.URL, state)
+	if !reopened.Enrolled() {
+		t.Fatal("registro salvo não foi carregado")
+	}
+	if got := reopened.MachineID(&model.MachineInfo{Hostname: "outro"}); got != want {
+		t.Errorf("MachineID = %q, quer %q", got, want)
+	}
+}
+
+func TestMachineIDComputedOnce(t *testing.T) {
+	client := newTestClient(t, "http://localhost", filepath.Join(t.TempDir(), "enrollment.json"))
+
+	if got := client.MachineID(nil); got != "" {
+		t.Errorf("MachineID(nil) sem registro = %q, quer vazio", got)
+	}
+	first := client.MachineID(testMachine())
+	if got := client.MachineID(&model.MachineInfo{Hostname: "outro"}); got != first {
+		t.Errorf("MachineID mudou de %q para %q", first, got)
+	}
+	if got := client.MachineID(nil); got != first {
+		t.Errorf("MachineID(nil) = %q, quer %q", got, first)
+	}
+}
+
+func TestSendRetriesTemporaryErrors(t *testing.T) {
+	server := newFakeServer(t)
+	client := newTestClient(t, server.URL, filepath.Join(t.TempDir(), "enrollment.json"))
+	if err := client.Enroll(context.Background(), testMachine()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Duas falhas temporárias e depois sucesso, dentro das 3 tentativas
+	server.setStatus(func(attempt int) int {
+		if attempt == 1 {
+			return http.StatusServiceUnavailable
+		}
+		if attempt == 2 {
+			return http.StatusTooManyRequests
+		}
+		return http.StatusOK
+	})
+	report, _ := NewReport(KindInventory, client.MachineID(nil), map[string]string{"a": "b"})
+	if err := client.Send(context.Background(), report); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if server.attempts != 3 || len(server.received()) != 1 {
+		t.Errorf("tentativas = %d, recebidos = %d", server.attempts, len(server.received()))
+	}
+
+	// Falhas além do limite de tentativas são devolvidas
+	server.setStatus(func(int) int { return http.StatusBadGateway })
+	if err := client.Send(context.Background(), report); err == nil || !strings.Contains(err.Error(), "3 tentativas") {
+		t.Errorf("Send com servidor fora = %v", err)
+	}
+}
+
+func TestSendDoesNotRetryRejected(t *testing.T) {
+	server := newFakeServer(t)
+	client := newTestClient(t, server.URL, filepath.Join(t.TempDir(), "enrollment.json"))
+	if err := client.Enroll(context.Background(), testMachine()); err != nil {
+		t.Fatal(err)
+	}
+
+	server.setStatus(func(int) int { return http.StatusBadRequest })
+	report, _ := NewReport(KindInventory, client.MachineID(nil), nil)
+	if err := client.Send(context.Background(), report); !errors.Is(err, ErrRejected) {
+		t.Fatalf("Send = %v, quer ErrRejected", err)
+	}
+	if server.attempts != 1 {
+		t.Errorf("tentativas = %d, quer 1", server.attempts)
+	}
+}
+
+func TestSendUnauthorizedKeepsMachineID(t *testing.T) {
+	server := newFakeServer(t)
+	state := filepath.Join(t.TempDir(), "enrollment.json")
+	client := newTestClient(t, server.URL, state)
+	if err := client.Enroll(context.Background(), testMachine()); err != nil {
+		t.Fatal(err)
+	}
+	id := client.MachineID(nil)
+
+	server.setStatus(func(int) int { return http.StatusUnauthorized })
+	report, _ := NewReport(KindInventory, id, nil)
+	if err := client.Send(context.Background(), report); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Send = %v, quer ErrUnauthorized", err)
+	}
+	if client.Enrolled() {
+		t.Error("token recusado continuou valendo")
+	}
+
+	reopened := newTestClient(t, server.URL, state)
+	if reopened.Enrolled() {
+		t.Error("token recusado foi salvo")
+	}
+	if got := reopened.MachineID(&model.MachineInfo{Hostname: "outro"}); got != id {
+		t.Errorf("MachineID após recusa = %q, quer %q", got, id)
+	}
+}
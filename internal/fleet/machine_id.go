@@ -0,0 +1,36 @@
+package fleet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// MachineID deriva um identificador estável da máquina a partir do serial
+// geral e do serial da placa-mãe, que não mudam com reinstalações do
+// sistema. O hostname só é usado quando nenhum serial é conhecido.
+func MachineID(info *model.MachineInfo) string {
+	var parts []string
+	for _, serial := range []string{info.SerialNumber, info.MotherboardSN} {
+		if validSerial(serial) {
+			parts = append(parts, strings.ToLower(strings.TrimSpace(serial)))
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "hostname:"+strings.ToLower(info.Hostname))
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// validSerial descarta os valores genéricos que o firmware costuma reportar
+func validSerial(serial string) bool {
+	switch strings.ToLower(strings.TrimSpace(serial)) {
+	case "", "unknown", "n/a", "none", "default string", "to be filled by o.e.m.", "0", "system serial number":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,294 @@
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/diskhealth"
+	"github.com/dev/falcon-agent/internal/metrics"
+	"github.com/dev/falcon-agent/internal/model"
+	"github.com/dev/falcon-agent/internal/spool"
+	"github.com/dev/falcon-agent/internal/usb"
+	"github.com/dev/falcon-agent/pkg/logger"
+)
+
+// Source fornece os dados enviados ao servidor
+type Source interface {
+	MachineInfo() *model.MachineInfo
+	Metrics() *metrics.SystemMetrics
+}
+
+// inventoryRefresh é o intervalo máximo entre dois envios do inventário.
+// Fora isso ele só é enviado quando muda, mas o reenvio periódico mantém
+// atualizados no servidor o tempo ligado e o uso das partições.
+const inventoryRefresh = 24 * time.Hour
+
+// MetricsBatch são os pontos de cada métrica coletados desde o último envio
+type MetricsBatch map[string][]metrics.MetricPoint
+
+// Reporter registra o agente e envia ao servidor central o inventário,
+// quando muda ou a cada inventoryRefresh, as métricas e os eventos USB e de
+// saúde dos discos. Todo relatório passa antes pela fila em disco, de modo
+// que nada se perde enquanto o servidor está fora do ar ou o agente é
+// reiniciado; a fila é esvaziada em ordem assim que o envio volta a
+// funcionar.
+type Reporter struct {
+	client   *Client
+	spool    *spool.Spool
+	source   Source
+	interval time.Duration
+	logger   logger.Logger
+
+	// wake antecipa o envio quando um evento entra na fila
+	wake chan struct{}
+
+	// lastMetrics é o horário do último ponto de métrica já enfileirado
+	lastMetrics time.Time
+	// inventoryHash e inventoryAt identificam o último inventário
+	// enfileirado e quando ele entrou na fila
+	inventoryHash []byte
+	inventoryAt   time.Time
+}
+
+// NewReporter cria o agendador de envios usando a fila informada
+func NewReporter(client *Client, queue *spool.Spool, source Source, interval time.Duration, log logger.Logger) *Reporter {
+	return &Reporter{
+		client:   client,
+		spool:    queue,
+		source:   source,
+		interval: interval,
+		logger:   log,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Run enfileira um relatório imediatamente e depois a cada intervalo,
+// entregando a fila ao servidor, até o contexto ser cancelado
+func (r *Reporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	collect := true
+	for {
+		if collect {
+			if err := r.enqueueReports(); err != nil {
+				r.logger.Error("Erro ao enfileirar relatório: %v", err)
+			}
+		}
+		if err := r.deliver(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Error("Erro ao enviar relatório ao servidor (%d pendentes na fila): %v", r.spool.Len(), err)
+		}
+
+		select {
+		case <-ticker.C:
+			collect = true
+		case <-r.wake:
+			collect = false
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// HandleUSBEvent enfileira o evento USB e antecipa o envio. Pode ser
+// registrado diretamente em Agent.OnUSBEvent.
+func (r *Reporter) HandleUSBEvent(event usb.Event) {
+	machineID := r.client.MachineID(r.source.MachineInfo())
+	if err := r.enqueue(KindUSBEvent, machineID, event); err != nil {
+		r.logger.Error("Erro ao enfileirar evento USB: %v", err)
+		return
+	}
+	r.wakeUp()
+}
+
+// HandleDiskHealthEvents enfileira os avisos de piora na saúde dos discos
+// e antecipa o envio. Pode ser registrado diretamente em
+// Agent.OnDiskHealthEvent.
+func (r *Reporter) HandleDiskHealthEvents(events []diskhealth.Event) {
+	machineID := r.client.MachineID(r.source.MachineInfo())
+	for _, event := range events {
+		if err := r.enqueue(KindDiskHealthEvent, machineID, event); err != nil {
+			r.logger.Error("Erro ao enfileirar aviso de saúde do disco: %v", err)
+			return
+		}
+	}
+	r.wakeUp()
+}
+
+// wakeUp antecipa a entrega da fila sem esperar o próximo intervalo
+func (r *Reporter) wakeUp() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// enqueueReports coloca na fila o inventário, quando mudou desde o último
+// envio, e as métricas novas
+func (r *Reporter) enqueueReports() error {
+	info := r.source.MachineInfo()
+	if info == nil {
+		return nil
+	}
+	machineID := r.client.MachineID(info)
+
+	if err := r.enqueueInventory(machineID, info); err != nil {
+		return err
+	}
+
+	batch, last := r.pendingMetrics()
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := r.enqueue(KindMetrics, machineID, batch); err != nil {
+		return err
+	}
+	r.lastMetrics = last
+	return nil
+}
+
+// enqueueInventory enfileira o inventário se ele mudou ou se o último envio
+// passou de inventoryRefresh. Na fila fica apenas o inventário mais recente:
+// um ainda não entregue é substituído pelo novo.
+func (r *Reporter) enqueueInventory(machineID string, info *model.MachineInfo) error {
+	hash, err := inventoryHash(info)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(hash, r.inventoryHash) && time.Since(r.inventoryAt) < inventoryRefresh {
+		return nil
+	}
+	if err := r.enqueue(KindInventory, machineID, info); err != nil {
+		return err
+	}
+	r.inventoryHash = hash
+	r.inventoryAt = time.Now()
+	return nil
+}
+
+// inventoryHash resume o inventário ignorando os valores que mudam a cada
+// coleta (tempo ligado e uso das partições)
+func inventoryHash(info *model.MachineInfo) ([]byte, error) {
+	cp := *info
+	cp.OSDetails.UptimeSeconds = 0
+	cp.HDs = make([]model.HDInfo, len(info.HDs))
+	for i, hd := range info.HDs {
+		hd.Partitions = append([]model.PartitionInfo(nil), hd.Partitions...)
+		for j := range hd.Partitions {
+			hd.Partitions[j].UsedBytes = 0
+			hd.Partitions[j].FreeBytes = 0
+		}
+		cp.HDs[i] = hd
+	}
+
+	data, err := json.Marshal(&cp)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return sum[:], nil
+}
+
+// enqueue serializa o relatório e o grava na fila. O inventário substitui
+// o que ainda estiver pendente na fila, já que só o mais recente interessa.
+func (r *Reporter) enqueue(kind, machineID string, data any) error {
+	report, err := NewReport(kind, machineID, data)
+	if err != nil {
+		return err
+	}
+	record, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	var evicted int
+	if kind == KindInventory {
+		evicted, err = r.spool.Replace(kind, record)
+	} else {
+		evicted, err = r.spool.Push(record)
+	}
+	if err != nil {
+		return err
+	}
+	if evicted > 0 {
+		r.logger.Warn("Fila de envio cheia: %d relatórios antigos descartados", evicted)
+	}
+	return nil
+}
+
+// deliver garante o registro do agente e envia a fila em ordem. O envio
+// para no primeiro erro temporário para preservar a ordem dos relatórios.
+func (r *Reporter) deliver(ctx context.Context) error {
+	if r.spool.Len() == 0 {
+		return nil
+	}
+
+	info := r.source.MachineInfo()
+	if info == nil {
+		return nil
+	}
+
+	machineID := r.client.MachineID(info)
+	if !r.client.Enrolled() {
+		if err := r.client.Enroll(ctx, info); err != nil {
+			return err
+		}
+		r.logger.Info("Agente registrado no servidor com o ID %s", machineID)
+	}
+
+	for {
+		seq, data, ok, err := r.spool.Peek()
+		if !ok {
+			return nil
+		}
+		if err != nil {
+			r.logger.Error("Descartando relatório ilegível da fila: %v", err)
+			r.spool.Remove(seq)
+			continue
+		}
+
+		var report Report
+		if err := json.Unmarshal(data, &report); err != nil {
+			r.logger.Error("Descartando relatório %d corrompido da fila: %v", seq, err)
+			r.spool.Remove(seq)
+			continue
+		}
+		if report.MachineID == "" {
+			report.MachineID = machineID
+		}
+
+		if err := r.client.Send(ctx, report); err != nil {
+			if !errors.Is(err, ErrRejected) {
+				return err
+			}
+			r.logger.Warn("Relatório %s recusado pelo servidor e descartado: %v", report.Kind, err)
+		}
+
+		if err := r.spool.Remove(seq); err != nil {
+			return err
+		}
+	}
+}
+
+// pendingMetrics retorna os pontos posteriores ao último envio e o
+// horário do ponto mais recente entre eles
+func (r *Reporter) pendingMetrics() (MetricsBatch, time.Time) {
+	batch := make(MetricsBatch)
+	last := r.lastMetrics
+	for name, history := range r.source.Metrics().Histories() {
+		for _, p := range history.GetPoints() {
+			if !p.Timestamp.After(r.lastMetrics) {
+				continue
+			}
+			batch[name] = append(batch[name], p)
+			if p.Timestamp.After(last) {
+				last = p.Timestamp
+			}
+		}
+	}
+	return batch, last
+}
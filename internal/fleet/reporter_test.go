@@ -0,0 +1,189 @@
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/metrics"
+	"github.com/dev/falcon-agent/internal/model"
+	"github.com/dev/falcon-agent/internal/spool"
+	"github.com/dev/falcon-agent/internal/usb"
+	"github.com/dev/falcon-agent/pkg/logger"
+)
+
+type fakeSource struct {
+	info    *model.MachineInfo
+	metrics *metrics.SystemMetrics
+}
+
+func (s *fakeSource) MachineInfo() *model.MachineInfo { return s.info }
+func (s *fakeSource) Metrics() *metrics.SystemMetrics { return s.metrics }
+
+func newTestReporter(t *testing.T, serverURL, dir string) *Reporter {
+	t.Helper()
+	client := newTestClient(t, serverURL, filepath.Join(dir, "enrollment.json"))
+	client.config.Backoff = testBackoff(1)
+	queue, err := spool.Open(filepath.Join(dir, "spool"), spool.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := &fakeSource{info: testMachine(), metrics: metrics.NewSystemMetrics()}
+	return NewReporter(client, queue, source, time.Minute, logger.Default())
+}
+
+func usbEvent(port string) usb.Event {
+	return usb.Event{Type: usb.DeviceAttached, Port: port, Time: time.Now()}
+}
+
+func TestReporterReplaysSpoolInOrder(t *testing.T) {
+	server := newFakeServer(t)
+	dir := t.TempDir()
+	reporter := newTestReporter(t, server.URL, dir)
+
+	// Com o servidor fora do ar os eventos ficam na fila
+	server.setStatus(func(int) int { return http.StatusServiceUnavailable })
+	for _, port := range []string{"1-1", "1-2", "1-3"} {
+		reporter.HandleUSBEvent(usbEvent(port))
+	}
+	if err := reporter.deliver(context.Background()); err == nil {
+		t.Fatal("deliver com servidor fora do ar não falhou")
+	}
+	if n := reporter.spool.Len(); n != 3 {
+		t.Fatalf("fila com %d relatórios, quer 3", n)
+	}
+
+	// Depois de reiniciar o agente, a fila é entregue na ordem original
+	server.setStatus(nil)
+	reporter = newTestReporter(t, server.URL, dir)
+	if err := reporter.deliver(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := reporter.spool.Len(); n != 0 {
+		t.Errorf("fila com %d relatórios após a entrega", n)
+	}
+
+	reports := server.received()
+	if len(reports) != 3 {
+		t.Fatalf("servidor recebeu %d relatórios, quer 3", len(reports))
+	}
+	for i, want := range []string{"1-1", "1-2", "1-3"} {
+		var event usb.Event
+		if err := json.Unmarshal(reports[i].Data, &event); err != nil {
+			t.Fatal(err)
+		}
+		if reports[i].Kind != KindUSBEvent || event.Port != want {
+			t.Errorf("relatório %d = %s %s, quer %s %s", i, reports[i].Kind, event.Port, KindUSBEvent, want)
+		}
+		if reports[i].MachineID != MachineID(testMachine()) {
+			t.Errorf("relatório %d com machine_id %q", i, reports[i].MachineID)
+		}
+	}
+}
+
+func TestReporterDropsRejectedReports(t *testing.T) {
+	server := newFakeServer(t)
+	reporter := newTestReporter(t, server.URL, t.TempDir())
+
+	// O primeiro relatório é recusado e não deve travar os seguintes
+	server.setStatus(func(attempt int) int {
+		if attempt == 1 {
+			return http.StatusUnprocessableEntity
+		}
+		return http.StatusOK
+	})
+	reporter.HandleUSBEvent(usbEvent("1-1"))
+	reporter.HandleUSBEvent(usbEvent("1-2"))
+	if err := reporter.deliver(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := reporter.spool.Len(); n != 0 {
+		t.Errorf("fila com %d relatórios após a entrega", n)
+	}
+	if reports := server.received(); len(reports) != 1 {
+		t.Errorf("servidor recebeu %d relatórios, quer 1", len(reports))
+	}
+}
+
+func TestReporterEnqueuesNewMetricsOnly(t *testing.T) {
+	server := newFakeServer(t)
+	reporter := newTestReporter(t, server.URL, t.TempDir())
+	source := reporter.source.(*fakeSource)
+
+	now := time.Now()
+	source.metrics.Record("cpu_usage", now.Add(-2*time.Second), 10)
+	source.metrics.Record("cpu_usage", now.Add(-time.Second), 20)
+	if err := reporter.enqueueReports(); err != nil {
+		t.Fatal(err)
+	}
+	source.metrics.Record("cpu_usage", now, 30)
+	if err := reporter.enqueueReports(); err != nil {
+		t.Fatal(err)
+	}
+	if err := reporter.deliver(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var batches []MetricsBatch
+	for _, report := range server.received() {
+		if report.Kind != KindMetrics {
+			continue
+		}
+		var batch MetricsBatch
+		if err := json.Unmarshal(report.Data, &batch); err != nil {
+			t.Fatal(err)
+		}
+		batches = append(batches, batch)
+	}
+	if len(batches) != 2 || len(batches[0]["cpu_usage"]) != 2 || len(batches[1]["cpu_usage"]) != 1 {
+		t.Fatalf("lotes de métricas = %+v", batches)
+	}
+	if got := batches[1]["cpu_usage"][0].Value; got != 30 {
+		t.Errorf("segundo lote com o valor %v, quer 30", got)
+	}
+}
+
+func TestReporterEnqueuesChangedInventoryOnly(t *testing.T) {
+	server := newFakeServer(t)
+	reporter := newTestReporter(t, server.URL, t.TempDir())
+	source := reporter.source.(*fakeSource)
+
+	// Com o servidor fora do ar, só o inventário mais recente fica na fila
+	server.setStatus(func(int) int { return http.StatusServiceUnavailable })
+	enqueue := func() {
+		t.Helper()
+		if err := reporter.enqueueReports(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	enqueue()
+	source.info.OSDetails.UptimeSeconds += 60
+	enqueue()
+	if n := reporter.spool.Len(); n != 1 {
+		t.Fatalf("fila com %d relatórios, quer 1: o tempo ligado não é mudança", n)
+	}
+	source.info.Hostname = "estacao-02"
+	enqueue()
+	if n := reporter.spool.Len(); n != 1 {
+		t.Fatalf("fila com %d relatórios, quer só o inventário mais recente", n)
+	}
+
+	server.setStatus(nil)
+	if err := reporter.deliver(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	reports := server.received()
+	if len(reports) != 1 || reports[0].Kind != KindInventory {
+		t.Fatalf("servidor recebeu %+v, quer um inventário", reports)
+	}
+	var info model.MachineInfo
+	if err := json.Unmarshal(reports[0].Data, &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Hostname != "estacao-02" {
+		t.Errorf("inventário enviado com hostname %q, quer estacao-02", info.Hostname)
+	}
+}
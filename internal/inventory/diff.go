@@ -0,0 +1,223 @@
+package inventory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// Component identifica a parte do inventário que mudou
+type Component string
+
+const (
+	ComponentMemory   Component = "memory"
+	ComponentDisk     Component = "disk"
+	ComponentUSB      Component = "usb"
+	ComponentBIOS     Component = "bios"
+	ComponentHostname Component = "hostname"
+)
+
+// ChangeType indica se o item foi adicionado, removido ou alterado
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+// Change descreve uma diferença entre dois inventários
+type Change struct {
+	Time      time.Time  `json:"time"`
+	Component Component  `json:"component"`
+	Type      ChangeType `json:"type"`
+	// Key identifica o item dentro do componente (slot, serial, porta...)
+	Key string `json:"key"`
+	// Old e New são o item antes e depois da mudança
+	Old any `json:"old,omitempty"`
+	New any `json:"new,omitempty"`
+	// Summary é uma descrição legível da mudança
+	Summary string `json:"summary"`
+}
+
+// collectorFor associa cada componente ao coletor que o preenche; quando o
+// coletor falha, o componente é ignorado para não reportar remoções falsas
+var collectorFor = map[Component]string{
+	ComponentMemory:   "memory",
+	ComponentDisk:     "disks",
+	ComponentUSB:      "usb",
+	ComponentBIOS:     "bios",
+	ComponentHostname: "hostname",
+}
+
+// Diff compara dois inventários e retorna as mudanças de memória, discos,
+// dispositivos USB, versão da BIOS e hostname. Retorna nil quando old é nil.
+func Diff(old, new *model.MachineInfo) []Change {
+	if old == nil || new == nil {
+		return nil
+	}
+
+	now := time.Now()
+	var changes []Change
+	add := func(c Change) {
+		c.Time = now
+		changes = append(changes, c)
+	}
+	skip := func(component Component) bool {
+		_, failed := new.CollectorErrors[collectorFor[component]]
+		return failed
+	}
+
+	if !skip(ComponentHostname) && old.Hostname != new.Hostname {
+		add(Change{
+			Component: ComponentHostname,
+			Type:      ChangeChanged,
+			Key:       "hostname",
+			Old:       old.Hostname,
+			New:       new.Hostname,
+			Summary:   fmt.Sprintf("Hostname alterado de %s para %s", old.Hostname, new.Hostname),
+		})
+	}
+
+	if !skip(ComponentBIOS) && old.BIOS.Version != new.BIOS.Version {
+		add(Change{
+			Component: ComponentBIOS,
+			Type:      ChangeChanged,
+			Key:       "version",
+			Old:       old.BIOS,
+			New:       new.BIOS,
+			Summary:   fmt.Sprintf("BIOS atualizada de %s para %s", old.BIOS.Version, new.BIOS.Version),
+		})
+	}
+
+	if !skip(ComponentMemory) {
+		diffItems(ComponentMemory, old.Memory, new.Memory, memoryKey, equal[model.MemoryInfo], describeMemory, add)
+	}
+	if !skip(ComponentDisk) {
+		diffItems(ComponentDisk, old.HDs, new.HDs, diskKey, diskEqual, describeDisk, add)
+	}
+	if !skip(ComponentUSB) {
+		diffItems(ComponentUSB, old.USBDevices, new.USBDevices, usbKey, usbEqual, describeUSB, add)
+	}
+
+	return changes
+}
+
+// diffItems compara duas listas pelo identificador de cada item. Itens com
+// a mesma chave e conteúdo diferente são reportados como alterados.
+func diffItems[T any](component Component, old, new []T, key func(T) string, eq func(a, b T) bool, describe func(T) string, add func(Change)) {
+	oldByKey := make(map[string]T, len(old))
+	for _, item := range old {
+		oldByKey[key(item)] = item
+	}
+	newByKey := make(map[string]T, len(new))
+	for _, item := range new {
+		newByKey[key(item)] = item
+	}
+
+	for _, item := range old {
+		k := key(item)
+		if _, ok := newByKey[k]; !ok {
+			add(Change{Component: component, Type: ChangeRemoved, Key: k, Old: item,
+				Summary: "Removido: " + describe(item)})
+		}
+	}
+	for _, item := range new {
+		k := key(item)
+		previous, ok := oldByKey[k]
+		switch {
+		case !ok:
+			add(Change{Component: component, Type: ChangeAdded, Key: k, New: item,
+				Summary: "Adicionado: " + describe(item)})
+		case !eq(previous, item):
+			add(Change{Component: component, Type: ChangeChanged, Key: k, Old: previous, New: item,
+				Summary: fmt.Sprintf("Alterado: %s → %s", describe(previous), describe(item))})
+		}
+	}
+}
+
+func equal[T comparable](a, b T) bool {
+	return a == b
+}
+
+// memoryKey identifica o módulo pelo slot, de modo que a troca de um pente
+// aparece como alteração do slot
+func memoryKey(m model.MemoryInfo) string {
+	if m.Slot != "" {
+		return m.Slot
+	}
+	return m.SerialNumber
+}
+
+func describeMemory(m model.MemoryInfo) string {
+	return fmt.Sprintf("memória %d MB %s no slot %s (serial %s)", m.SizeMB, m.Manufacturer, m.Slot, m.SerialNumber)
+}
+
+// diskKey identifica o disco pelo serial, de modo que a troca de um disco
+// aparece como remoção do antigo e adição do novo
+func diskKey(d model.HDInfo) string {
+	if d.Serial != "" {
+		return d.Serial
+	}
+	if d.Model != "" {
+		return d.Model
+	}
+	return d.Name
+}
+
+// diskEqual compara apenas o hardware: o nome do dispositivo pode mudar
+// entre reinícios e o uso das partições muda a cada coleta
+func diskEqual(a, b model.HDInfo) bool {
+	return a.Model == b.Model && a.Serial == b.Serial && a.SizeGB == b.SizeGB
+}
+
+func describeDisk(d model.HDInfo) string {
+	return fmt.Sprintf("disco %s de %d GB (serial %s)", d.Model, d.SizeGB, d.Serial)
+}
+
+// usbEqual ignora as classes de interface, que nem sempre são conhecidas
+// no momento da coleta e gerariam alterações falsas
+func usbEqual(a, b model.USBDevice) bool {
+	return a.VendorID == b.VendorID && a.ProductID == b.ProductID &&
+		a.Name == b.Name && a.Serial == b.Serial && a.Port == b.Port
+}
+
+// usbKey identifica o dispositivo pelo modelo e pela porta, de modo que
+// outro dispositivo na mesma porta aparece como remoção e adição
+func usbKey(d model.USBDevice) string {
+	return fmt.Sprintf("%s:%s@%s", d.VendorID, d.ProductID, d.Port)
+}
+
+func describeUSB(d model.USBDevice) string {
+	return fmt.Sprintf("USB %s (%s:%s) na porta %s", d.Name, d.VendorID, d.ProductID, d.Port)
+}
+
+// Baseline retorna o inventário a ser usado como referência na próxima
+// comparação: o novo inventário, mantendo do anterior os componentes cujo
+// coletor falhou, para que eles não apareçam como adicionados depois
+func Baseline(old, new *model.MachineInfo) *model.MachineInfo {
+	if old == nil || len(new.CollectorErrors) == 0 {
+		return new
+	}
+
+	baseline := *new
+	for component, collector := range collectorFor {
+		if _, failed := new.CollectorErrors[collector]; !failed {
+			continue
+		}
+		switch component {
+		case ComponentMemory:
+			baseline.Memory = old.Memory
+		case ComponentDisk:
+			baseline.HDs = old.HDs
+		case ComponentUSB:
+			baseline.USBDevices = old.USBDevices
+		case ComponentBIOS:
+			baseline.BIOS = old.BIOS
+		case ComponentHostname:
+			baseline.Hostname = old.Hostname
+		}
+	}
+	return &baseline
+}
@@ -0,0 +1,100 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+func testInventory() *model.MachineInfo {
+	return &model.MachineInfo{
+		Hostname: "estacao-01",
+		BIOS:     model.BIOSInfo{Vendor: "Dell", Version: "1.2.0"},
+		Memory: []model.MemoryInfo{
+			{Slot: "DIMM1", SizeMB: 8192, Manufacturer: "Kingston", SerialNumber: "K1"},
+			{Slot: "DIMM2", SizeMB: 8192, Manufacturer: "Kingston", SerialNumber: "K2"},
+		},
+		HDs: []model.HDInfo{
+			{Name: "sda", Model: "Samsung SSD", Serial: "S1", SizeGB: 500,
+				Partitions: []model.PartitionInfo{{Name: "sda1", UsedBytes: 100, FreeBytes: 900}}},
+		},
+		USBDevices: []model.USBDevice{
+			{VendorID: "046d", ProductID: "c52b", Name: "Receptor", Port: "1-1"},
+		},
+	}
+}
+
+// changeSet resume as mudanças como componente/tipo/chave
+func changeSet(changes []Change) map[string]bool {
+	set := make(map[string]bool, len(changes))
+	for _, c := range changes {
+		set[string(c.Component)+"/"+string(c.Type)+"/"+c.Key] = true
+	}
+	return set
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	old, new := testInventory(), testInventory()
+	// Uso das partições e nome do dispositivo não são mudanças de hardware
+	new.HDs[0].Name = "sdb"
+	new.HDs[0].Partitions[0].UsedBytes = 500
+	new.USBDevices[0].InterfaceClasses = []string{"03"}
+
+	if changes := Diff(old, new); len(changes) != 0 {
+		t.Errorf("Diff = %+v, quer nenhuma mudança", changes)
+	}
+	if changes := Diff(nil, new); changes != nil {
+		t.Errorf("Diff sem inventário anterior = %+v", changes)
+	}
+}
+
+func TestDiffComponents(t *testing.T) {
+	old, new := testInventory(), testInventory()
+	new.Hostname = "estacao-02"
+	new.BIOS.Version = "1.3.0"
+	new.Memory[1] = model.MemoryInfo{Slot: "DIMM2", SizeMB: 16384, Manufacturer: "Crucial", SerialNumber: "C1"}
+	new.HDs[0] = model.HDInfo{Name: "sda", Model: "WD Blue", Serial: "W1", SizeGB: 1000}
+	new.USBDevices = append(new.USBDevices, model.USBDevice{VendorID: "0781", ProductID: "5581", Port: "1-2"})
+
+	got := changeSet(Diff(old, new))
+	want := []string{
+		"hostname/changed/hostname",
+		"bios/changed/version",
+		"memory/changed/DIMM2",
+		"disk/removed/S1",
+		"disk/added/W1",
+		"usb/added/0781:5581@1-2",
+	}
+	for _, key := range want {
+		if !got[key] {
+			t.Errorf("mudança %s não reportada", key)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("Diff reportou %d mudanças, quer %d: %v", len(got), len(want), got)
+	}
+}
+
+func TestDiffSkipsFailedCollectors(t *testing.T) {
+	old, new := testInventory(), testInventory()
+	new.Memory = nil
+	new.USBDevices = nil
+	new.CollectorErrors = map[string]string{"memory": "dmidecode indisponível"}
+
+	got := changeSet(Diff(old, new))
+	if got["memory/removed/DIMM1"] || got["memory/removed/DIMM2"] {
+		t.Error("falha do coletor de memória reportada como remoção")
+	}
+	if !got["usb/removed/046d:c52b@1-1"] {
+		t.Error("remoção do USB não reportada")
+	}
+
+	// A referência seguinte mantém a memória anterior
+	baseline := Baseline(old, new)
+	if len(baseline.Memory) != 2 || len(baseline.USBDevices) != 0 {
+		t.Errorf("Baseline = memória %d, USB %d; quer 2, 0", len(baseline.Memory), len(baseline.USBDevices))
+	}
+	if changes := Diff(baseline, testInventory()); len(changeSet(changes)) != 1 {
+		t.Errorf("Diff após a falha = %+v, quer só o USB adicionado", changes)
+	}
+}
@@ -0,0 +1,115 @@
+package inventory
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// History guarda em disco as mudanças do inventário, em JSON, uma por linha
+type History struct {
+	mu       sync.Mutex
+	filename string
+	file     *os.File
+}
+
+// OpenHistory abre (ou cria) o arquivo de histórico para escrita no final
+func OpenHistory(filename string) (*History, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório do histórico de inventário: %v", err)
+	}
+
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir histórico de inventário: %v", err)
+	}
+	return &History{filename: filename, file: file}, nil
+}
+
+// Record grava as mudanças no histórico
+func (h *History) Record(changes []Change) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, change := range changes {
+		data, err := json.Marshal(change)
+		if err != nil {
+			return err
+		}
+		if _, err := h.file.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Recent retorna as últimas mudanças registradas, da mais antiga para a
+// mais recente. Com limit <= 0 retorna todo o histórico.
+func (h *History) Recent(limit int) ([]Change, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	file, err := os.Open(h.filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var changes []Change
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var change Change
+		if json.Unmarshal(scanner.Bytes(), &change) != nil {
+			continue
+		}
+		changes = append(changes, change)
+		if limit > 0 && len(changes) > limit {
+			changes = changes[1:]
+		}
+	}
+	return changes, scanner.Err()
+}
+
+// Close fecha o arquivo de histórico
+func (h *History) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.file.Close()
+}
+
+// LoadSnapshot lê o último inventário salvo, usado como referência para
+// detectar mudanças feitas com o agente parado (ex.: troca de memória)
+func LoadSnapshot(filename string) (*model.MachineInfo, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	info := &model.MachineInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("inventário salvo inválido: %v", err)
+	}
+	return info, nil
+}
+
+// SaveSnapshot grava o inventário de forma atômica
+func SaveSnapshot(filename string, info *model.MachineInfo) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("erro ao salvar inventário: %v", err)
+	}
+	return os.Rename(tmp, filename)
+}
@@ -1,15 +1,20 @@
 package metrics
 
 import (
+	"encoding/json"
+	"fmt"
+	"runtime"
 	"sync"
 	"time"
 )
 
-const maxDataPoints = 60 // 5 minutos de histórico (1 ponto a cada 5 segundos)
+// HistorySize é o número de pontos mantidos em memória por métrica (5
+// minutos com o intervalo padrão de 5 segundos)
+const HistorySize = 60
 
 type MetricPoint struct {
-	Timestamp time.Time
-	Value     float64
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
 }
 
 type MetricHistory struct {
@@ -19,23 +24,30 @@ type MetricHistory struct {
 
 func NewMetricHistory() *MetricHistory {
 	return &MetricHistory{
-		points: make([]MetricPoint, 0, maxDataPoints),
+		points: make([]MetricPoint, 0, HistorySize),
 	}
 }
 
-func (h *MetricHistory) Add(value float64) {
+// Add adiciona um valor com o horário atual e retorna o ponto criado
+func (h *MetricHistory) Add(value float64) MetricPoint {
+	return h.AddAt(time.Now(), value)
+}
+
+// AddAt adiciona um valor com o horário informado e retorna o ponto criado
+func (h *MetricHistory) AddAt(ts time.Time, value float64) MetricPoint {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	point := MetricPoint{
-		Timestamp: time.Now(),
+		Timestamp: ts,
 		Value:     value,
 	}
 
-	if len(h.points) >= maxDataPoints {
+	if len(h.points) >= HistorySize {
 		h.points = h.points[1:] // Remove o ponto mais antigo
 	}
 	h.points = append(h.points, point)
+	return point
 }
 
 func (h *MetricHistory) GetPoints() []MetricPoint {
@@ -47,14 +59,109 @@ func (h *MetricHistory) GetPoints() []MetricPoint {
 	return points
 }
 
+// Last retorna o ponto mais recente, se houver
+func (h *MetricHistory) Last() (MetricPoint, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.points) == 0 {
+		return MetricPoint{}, false
+	}
+	return h.points[len(h.points)-1], true
+}
+
+// MarshalJSON serializa o histórico como a lista de pontos
+func (h *MetricHistory) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.GetPoints())
+}
+
 type SystemMetrics struct {
-	CPUUsage    *MetricHistory
-	MemoryUsage *MetricHistory
+	CPUUsage *MetricHistory `json:"cpu_usage"`
+	// CPUCoreUsage tem um histórico por núcleo lógico, na ordem do sistema
+	CPUCoreUsage []*MetricHistory `json:"cpu_core_usage"`
+	MemoryUsage  *MetricHistory   `json:"memory_usage"`
+	SwapUsage    *MetricHistory   `json:"swap_usage"`
+	Load1        *MetricHistory   `json:"load1"`
+	Load5        *MetricHistory   `json:"load5"`
+	Load15       *MetricHistory   `json:"load15"`
+	// Taxas de leitura e escrita em disco e de tráfego de rede, em bytes/s
+	DiskReadRate  *MetricHistory `json:"disk_read_bytes"`
+	DiskWriteRate *MetricHistory `json:"disk_write_bytes"`
+	NetRecvRate   *MetricHistory `json:"net_recv_bytes"`
+	NetSentRate   *MetricHistory `json:"net_sent_bytes"`
+	// TopProcesses tem os processos que mais usaram CPU em cada amostra
+	TopProcesses *ProcessHistory `json:"top_processes"`
+
+	store *Store
 }
 
 func NewSystemMetrics() *SystemMetrics {
+	cores := make([]*MetricHistory, runtime.NumCPU())
+	for i := range cores {
+		cores[i] = NewMetricHistory()
+	}
+
 	return &SystemMetrics{
-		CPUUsage:    NewMetricHistory(),
-		MemoryUsage: NewMetricHistory(),
+		CPUUsage:     NewMetricHistory(),
+		CPUCoreUsage: cores,
+		MemoryUsage:  NewMetricHistory(),
+		SwapUsage:    NewMetricHistory(),
+		Load1:        NewMetricHistory(),
+		Load5:        NewMetricHistory(),
+		Load15:       NewMetricHistory(),
+
+		DiskReadRate:  NewMetricHistory(),
+		DiskWriteRate: NewMetricHistory(),
+		NetRecvRate:   NewMetricHistory(),
+		NetSentRate:   NewMetricHistory(),
+
+		TopProcesses: NewProcessHistory(),
+	}
+}
+
+// CoreMetricName retorna o nome da métrica de uso de um núcleo
+func CoreMetricName(core int) string {
+	return fmt.Sprintf("cpu_core_%d", core)
+}
+
+// Histories retorna os históricos em memória pelo nome da métrica
+func (m *SystemMetrics) Histories() map[string]*MetricHistory {
+	histories := map[string]*MetricHistory{
+		"cpu_usage":    m.CPUUsage,
+		"memory_usage": m.MemoryUsage,
+		"swap_usage":   m.SwapUsage,
+		"load1":        m.Load1,
+		"load5":        m.Load5,
+		"load15":       m.Load15,
+
+		"disk_read_bytes":  m.DiskReadRate,
+		"disk_write_bytes": m.DiskWriteRate,
+		"net_recv_bytes":   m.NetRecvRate,
+		"net_sent_bytes":   m.NetSentRate,
+	}
+	for i, core := range m.CPUCoreUsage {
+		histories[CoreMetricName(i)] = core
+	}
+	return histories
+}
+
+// AttachStore faz com que os próximos pontos também sejam gravados em disco
+func (m *SystemMetrics) AttachStore(store *Store) {
+	m.store = store
+}
+
+// Record adiciona um valor ao histórico em memória da métrica e, se houver
+// um Store associado, grava o ponto em disco. As séries de uma mesma
+// amostra usam o mesmo horário, para que possam ser alinhadas.
+func (m *SystemMetrics) Record(name string, ts time.Time, value float64) error {
+	history, ok := m.Histories()[name]
+	if !ok {
+		return fmt.Errorf("métrica desconhecida: %s", name)
+	}
+
+	point := history.AddAt(ts, value)
+	if m.store == nil {
+		return nil
 	}
+	return m.store.Append(name, point)
 }
@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProcessSample é a leitura de um processo em uma amostra. O uso de CPU é
+// a média desde a amostra anterior, em porcentagem da máquina inteira
+// (como em cpu_usage), para que os processos possam ser comparados com o
+// gráfico de CPU.
+type ProcessSample struct {
+	PID        int32     `json:"pid"`
+	User       string    `json:"user"`
+	Name       string    `json:"name"`
+	Command    string    `json:"command"`
+	CPUPercent float64   `json:"cpu_percent"`
+	RSSBytes   uint64    `json:"rss_bytes"`
+	OpenFiles  int32     `json:"open_files"`
+	StartTime  time.Time `json:"start_time"`
+}
+
+// Ordenações aceitas por SortProcesses
+const (
+	SortByCPU    = "cpu"
+	SortByMemory = "memory"
+	SortByFiles  = "files"
+	SortByPID    = "pid"
+	SortByName   = "name"
+)
+
+// SortProcesses ordena os processos pelo critério informado: maior uso de
+// CPU, de memória ou de arquivos abertos primeiro, ou PID e nome em ordem
+// crescente. Retorna false para um critério desconhecido.
+func SortProcesses(processes []ProcessSample, by string) bool {
+	var less func(a, b ProcessSample) bool
+	switch by {
+	case SortByCPU:
+		less = func(a, b ProcessSample) bool { return a.CPUPercent > b.CPUPercent }
+	case SortByMemory:
+		less = func(a, b ProcessSample) bool { return a.RSSBytes > b.RSSBytes }
+	case SortByFiles:
+		less = func(a, b ProcessSample) bool { return a.OpenFiles > b.OpenFiles }
+	case SortByPID:
+		less = func(a, b ProcessSample) bool { return a.PID < b.PID }
+	case SortByName:
+		less = func(a, b ProcessSample) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	default:
+		return false
+	}
+	// Empates ficam em ordem de PID para a lista não pular entre atualizações
+	sort.SliceStable(processes, func(i, j int) bool {
+		if less(processes[i], processes[j]) {
+			return true
+		}
+		if less(processes[j], processes[i]) {
+			return false
+		}
+		return processes[i].PID < processes[j].PID
+	})
+	return true
+}
+
+// FilterProcesses retorna os processos cujo PID, usuário, nome ou linha de
+// comando contém o texto buscado, sem diferenciar maiúsculas de minúsculas
+func FilterProcesses(processes []ProcessSample, query string) []ProcessSample {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return processes
+	}
+	var filtered []ProcessSample
+	for _, p := range processes {
+		text := strings.ToLower(strings.Join([]string{strconv.Itoa(int(p.PID)), p.User, p.Name, p.Command}, " "))
+		if strings.Contains(text, query) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// TopProcesses são os processos que mais usaram CPU em uma amostra, junto
+// com o uso total de CPU da máquina no mesmo instante
+type TopProcesses struct {
+	Timestamp time.Time       `json:"timestamp"`
+	CPUUsage  float64         `json:"cpu_usage"`
+	Processes []ProcessSample `json:"processes"`
+}
+
+// ProcessHistory guarda os maiores consumidores de CPU das últimas
+// HistorySize amostras, o mesmo período do histórico em memória das métricas
+type ProcessHistory struct {
+	mu      sync.RWMutex
+	entries []TopProcesses
+}
+
+func NewProcessHistory() *ProcessHistory {
+	return &ProcessHistory{
+		entries: make([]TopProcesses, 0, HistorySize),
+	}
+}
+
+// Add acrescenta uma amostra, descartando a mais antiga quando o histórico
+// está cheio
+func (h *ProcessHistory) Add(entry TopProcesses) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.entries) >= HistorySize {
+		h.entries = h.entries[1:]
+	}
+	h.entries = append(h.entries, entry)
+}
+
+func (h *ProcessHistory) GetEntries() []TopProcesses {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	entries := make([]TopProcesses, len(h.entries))
+	copy(entries, h.entries)
+	return entries
+}
+
+// Peak retorna a amostra do histórico com o maior uso de CPU da máquina
+func (h *ProcessHistory) Peak() (TopProcesses, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var peak TopProcesses
+	found := false
+	for _, entry := range h.entries {
+		if !found || entry.CPUUsage > peak.CPUUsage {
+			peak, found = entry, true
+		}
+	}
+	return peak, found
+}
+
+// MarshalJSON serializa o histórico como a lista de amostras
+func (h *ProcessHistory) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.GetEntries())
+}
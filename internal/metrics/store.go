@@ -0,0 +1,561 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolution é a granularidade dos dados armazenados
+type Resolution string
+
+const (
+	ResolutionRaw    Resolution = "raw"
+	ResolutionMinute Resolution = "1m"
+	ResolutionHour   Resolution = "1h"
+)
+
+// Duration retorna o tamanho do intervalo de agregação (zero para raw)
+func (r Resolution) Duration() time.Duration {
+	switch r {
+	case ResolutionMinute:
+		return time.Minute
+	case ResolutionHour:
+		return time.Hour
+	}
+	return 0
+}
+
+// ParseResolution converte o texto ("raw", "1m", "1h") em Resolution
+func ParseResolution(s string) (Resolution, error) {
+	switch r := Resolution(s); r {
+	case ResolutionRaw, ResolutionMinute, ResolutionHour:
+		return r, nil
+	}
+	return "", fmt.Errorf("resolução inválida: %s", s)
+}
+
+// rollupResolutions são as agregações mantidas automaticamente
+var rollupResolutions = []Resolution{ResolutionMinute, ResolutionHour}
+
+// dayLayout nomeia os arquivos de dados, um por dia (UTC)
+const dayLayout = "2006-01-02"
+
+var metricNamePattern = regexp.MustCompile(`^[a-z0-9_.]+$`)
+
+// StoreOptions define por quanto tempo cada resolução é mantida em disco
+type StoreOptions struct {
+	RawRetention    time.Duration
+	MinuteRetention time.Duration
+	HourRetention   time.Duration
+}
+
+// DefaultStoreOptions retorna as retenções padrão: 2 dias de dados brutos,
+// 30 dias de agregados por minuto e 1 ano de agregados por hora
+func DefaultStoreOptions() StoreOptions {
+	return StoreOptions{
+		RawRetention:    48 * time.Hour,
+		MinuteRetention: 30 * 24 * time.Hour,
+		HourRetention:   365 * 24 * time.Hour,
+	}
+}
+
+func (o StoreOptions) retention(res Resolution) time.Duration {
+	switch res {
+	case ResolutionMinute:
+		return o.MinuteRetention
+	case ResolutionHour:
+		return o.HourRetention
+	}
+	return o.RawRetention
+}
+
+// Aggregate é um ponto agregado em um intervalo. Para dados brutos
+// Min, Max e Avg são o próprio valor e Count é 1.
+type Aggregate struct {
+	Timestamp time.Time `json:"timestamp"`
+	Min       float64   `json:"min"`
+	Max       float64   `json:"max"`
+	Avg       float64   `json:"avg"`
+	Count     int       `json:"count"`
+}
+
+func (a *Aggregate) add(value float64) {
+	if a.Count == 0 {
+		a.Min, a.Max = value, value
+	} else {
+		a.Min = math.Min(a.Min, value)
+		a.Max = math.Max(a.Max, value)
+	}
+	a.Avg += (value - a.Avg) / float64(a.Count+1)
+	a.Count++
+}
+
+// Store é um banco de séries temporais embutido, somente de acréscimo.
+// Cada métrica é gravada em arquivos de texto diários por resolução
+// (<dir>/<resolução>/<métrica>/<AAAA-MM-DD>.log) e os agregados por minuto
+// e por hora são gerados conforme os pontos brutos chegam.
+type Store struct {
+	mu   sync.Mutex
+	dir  string
+	opts StoreOptions
+
+	files   map[string]*os.File
+	buckets map[string]*Aggregate
+}
+
+// OpenStore abre (ou cria) o banco no diretório informado
+func OpenStore(dir string, opts StoreOptions) (*Store, error) {
+	// Os agregados em andamento são reconstruídos a partir dos dados
+	// brutos após um reinício, então eles precisam cobrir ao menos uma hora
+	if opts.RawRetention < time.Hour {
+		return nil, fmt.Errorf("retenção de dados brutos deve ser de ao menos 1h")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de métricas: %v", err)
+	}
+
+	s := &Store{
+		dir:     dir,
+		opts:    opts,
+		files:   make(map[string]*os.File),
+		buckets: make(map[string]*Aggregate),
+	}
+	if err := s.recoverBuckets(time.Now()); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("erro ao recuperar agregados de métricas: %v", err)
+	}
+	return s, nil
+}
+
+// SetOptions troca as retenções usadas a partir da próxima limpeza
+func (s *Store) SetOptions(opts StoreOptions) error {
+	if opts.RawRetention < time.Hour {
+		return fmt.Errorf("retenção de dados brutos deve ser de ao menos 1h")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.opts = opts
+	return nil
+}
+
+// Append grava um ponto bruto e atualiza os agregados da métrica
+func (s *Store) Append(metric string, point MetricPoint) error {
+	if !metricNamePattern.MatchString(metric) {
+		return fmt.Errorf("nome de métrica inválido: %q", metric)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Os agregados vêm antes do dado bruto para que a reconstrução de um
+	// agregado em andamento não conte o ponto atual duas vezes
+	ts := point.Timestamp.UTC()
+	for _, res := range rollupResolutions {
+		if err := s.updateBucket(res, metric, ts, point.Value); err != nil {
+			return err
+		}
+	}
+
+	line := fmt.Sprintf("%d %s\n", ts.UnixMilli(), formatValue(point.Value))
+	return s.write(ResolutionRaw, metric, ts, line)
+}
+
+// updateBucket acumula o valor no agregado em andamento e grava o agregado
+// anterior quando o ponto pertence a um novo intervalo
+func (s *Store) updateBucket(res Resolution, metric string, ts time.Time, value float64) error {
+	key := string(res) + "/" + metric
+	start := ts.Truncate(res.Duration())
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &Aggregate{}
+		s.buckets[key] = bucket
+	}
+
+	if bucket.Count > 0 && start.After(bucket.Timestamp) {
+		if err := s.write(res, metric, bucket.Timestamp, formatAggregate(*bucket)); err != nil {
+			return err
+		}
+		*bucket = Aggregate{Timestamp: start}
+	}
+	if bucket.Count == 0 {
+		bucket.Timestamp = start
+	}
+
+	// Pontos fora de ordem anteriores ao intervalo atual não entram nos agregados
+	if !start.Before(bucket.Timestamp) {
+		bucket.add(value)
+	}
+	return nil
+}
+
+// recoverBuckets reconstrói, a partir dos dados brutos, os agregados do
+// intervalo do último ponto gravado de cada métrica. Os intervalos que já
+// terminaram são gravados, caso o processo tenha caído antes do Close; o
+// intervalo atual continua em memória e é completado pelos próximos pontos.
+func (s *Store) recoverBuckets(now time.Time) error {
+	names, err := s.Metrics()
+	if err != nil {
+		return err
+	}
+
+	for _, metric := range names {
+		points, err := s.lastRawDay(metric)
+		if err != nil {
+			return err
+		}
+		if len(points) == 0 {
+			continue
+		}
+		last := points[len(points)-1].Timestamp
+
+		for _, res := range rollupResolutions {
+			// Os intervalos dividem o dia em partes iguais, então os pontos
+			// de um intervalo estão todos no arquivo do mesmo dia
+			start := last.Truncate(res.Duration())
+			bucket := &Aggregate{Timestamp: start}
+			for _, p := range points {
+				if !p.Timestamp.Before(start) {
+					bucket.add(p.Avg)
+				}
+			}
+
+			key := string(res) + "/" + metric
+			if start.Add(res.Duration()).After(now) {
+				s.buckets[key] = bucket
+				continue
+			}
+			if !s.hasAggregate(res, metric, *bucket) {
+				if err := s.write(res, metric, start, formatAggregate(*bucket)); err != nil {
+					return err
+				}
+			}
+			s.buckets[key] = &Aggregate{}
+		}
+	}
+	return nil
+}
+
+// lastRawDay retorna os pontos brutos do dia mais recente da métrica
+func (s *Store) lastRawDay(metric string) ([]Aggregate, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, string(ResolutionRaw), metric, "*.log"))
+	if err != nil {
+		return nil, err
+	}
+	// Os nomes AAAA-MM-DD ficam em ordem cronológica
+	sort.Strings(files)
+	for i := len(files) - 1; i >= 0; i-- {
+		day, err := time.Parse(dayLayout, strings.TrimSuffix(filepath.Base(files[i]), ".log"))
+		if err != nil {
+			continue
+		}
+		return s.readFile(ResolutionRaw, metric, day)
+	}
+	return nil, nil
+}
+
+// hasAggregate indica se o agregado já foi gravado com os mesmos pontos,
+// como acontece quando o Close grava um intervalo que não mudou até a
+// próxima abertura
+func (s *Store) hasAggregate(res Resolution, metric string, bucket Aggregate) bool {
+	points, err := s.readFile(res, metric, bucket.Timestamp)
+	if err != nil {
+		return false
+	}
+	for _, p := range points {
+		if p.Timestamp.Equal(bucket.Timestamp) && p.Count == bucket.Count {
+			return true
+		}
+	}
+	return false
+}
+
+// write acrescenta uma linha ao arquivo do dia, mantendo apenas o arquivo
+// do dia atual aberto para cada métrica e resolução
+func (s *Store) write(res Resolution, metric string, ts time.Time, line string) error {
+	filename := s.filename(res, metric, ts)
+	key := string(res) + "/" + metric
+
+	file, ok := s.files[key]
+	if !ok || file.Name() != filename {
+		if ok {
+			file.Close()
+		}
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			return err
+		}
+		if err := truncateTornLine(filename); err != nil {
+			return fmt.Errorf("erro ao reparar arquivo de métricas: %v", err)
+		}
+		var err error
+		file, err = os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("erro ao abrir arquivo de métricas: %v", err)
+		}
+		s.files[key] = file
+	}
+
+	_, err := file.WriteString(line)
+	return err
+}
+
+// truncateTornLine remove do fim do arquivo uma linha sem quebra de linha,
+// deixada por uma escrita interrompida, para que a próxima linha não seja
+// emendada a ela
+func truncateTornLine(filename string) error {
+	file, err := os.OpenFile(filename, os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	// Procura a última quebra de linha em blocos, do fim para o início
+	buf := make([]byte, 4096)
+	end := info.Size()
+	for offset := end; offset > 0; {
+		n := int64(len(buf))
+		if offset < n {
+			n = offset
+		}
+		offset -= n
+		if _, err := file.ReadAt(buf[:n], offset); err != nil {
+			return err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			end = offset + int64(i) + 1
+			if end == info.Size() {
+				return nil
+			}
+			return file.Truncate(end)
+		}
+	}
+	return file.Truncate(0)
+}
+
+func (s *Store) filename(res Resolution, metric string, ts time.Time) string {
+	return filepath.Join(s.dir, string(res), metric, ts.UTC().Format(dayLayout)+".log")
+}
+
+// Query retorna os pontos da métrica no intervalo [from, to] na resolução
+// pedida, em ordem cronológica. O agregado ainda em andamento é incluído.
+func (s *Store) Query(metric string, from, to time.Time, res Resolution) ([]Aggregate, error) {
+	if !metricNamePattern.MatchString(metric) {
+		return nil, fmt.Errorf("nome de métrica inválido: %q", metric)
+	}
+	if _, err := ParseResolution(string(res)); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []Aggregate
+	for day := from.UTC().Truncate(24 * time.Hour); !day.After(to); day = day.Add(24 * time.Hour) {
+		points, err := s.readFile(res, metric, day)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range points {
+			if !p.Timestamp.Before(from) && !p.Timestamp.After(to) {
+				result = append(result, p)
+			}
+		}
+	}
+
+	if bucket, ok := s.buckets[string(res)+"/"+metric]; ok && bucket.Count > 0 {
+		if !bucket.Timestamp.Before(from) && !bucket.Timestamp.After(to) {
+			result = append(result, *bucket)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+	if res != ResolutionRaw {
+		result = latestAggregates(result)
+	}
+	return result, nil
+}
+
+// latestAggregates mantém apenas a última versão de cada intervalo. Um
+// intervalo gravado parcialmente pelo Close é gravado de novo, completo,
+// quando termina depois de o banco ser reaberto.
+func latestAggregates(points []Aggregate) []Aggregate {
+	result := points[:0]
+	for _, p := range points {
+		if n := len(result); n > 0 && result[n-1].Timestamp.Equal(p.Timestamp) {
+			result[n-1] = p
+			continue
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// readFile lê o arquivo do dia. Linhas inválidas e a última linha sem
+// quebra de linha, de uma escrita interrompida por queda do processo, são
+// ignoradas.
+func (s *Store) readFile(res Resolution, metric string, day time.Time) ([]Aggregate, error) {
+	file, err := os.Open(s.filename(res, metric, day))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var points []Aggregate
+	scanner := bufio.NewScanner(file)
+	scanner.Split(scanCompleteLines)
+	for scanner.Scan() {
+		if p, ok := parseLine(res, scanner.Text()); ok {
+			points = append(points, p)
+		}
+	}
+	return points, scanner.Err()
+}
+
+// scanCompleteLines é bufio.ScanLines sem a última linha quando ela não
+// termina em quebra de linha: um número cortado no meio ainda seria lido
+// como um valor válido
+func scanCompleteLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), nil, nil
+	}
+	return 0, nil, nil
+}
+
+// Metrics retorna os nomes das métricas com dados brutos armazenados
+func (s *Store) Metrics() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, string(ResolutionRaw)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// ApplyRetention remove os arquivos diários que já saíram do período de
+// retenção da sua resolução
+func (s *Store) ApplyRetention(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, res := range append([]Resolution{ResolutionRaw}, rollupResolutions...) {
+		cutoff := now.UTC().Add(-s.opts.retention(res))
+		files, err := filepath.Glob(filepath.Join(s.dir, string(res), "*", "*.log"))
+		if err != nil {
+			return err
+		}
+		for _, filename := range files {
+			day, err := time.Parse(dayLayout, strings.TrimSuffix(filepath.Base(filename), ".log"))
+			if err != nil {
+				continue
+			}
+			// O arquivo só sai quando o dia inteiro está fora da retenção
+			if day.Add(24 * time.Hour).Before(cutoff) {
+				if err := os.Remove(filename); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Close grava os agregados em andamento e fecha os arquivos abertos. Se o
+// banco for reaberto antes de o intervalo terminar, o agregado é
+// reconstruído dos dados brutos e gravado de novo ao final do intervalo.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	for key, bucket := range s.buckets {
+		if bucket.Count == 0 {
+			continue
+		}
+		res, metric, _ := strings.Cut(key, "/")
+		if err := s.write(Resolution(res), metric, bucket.Timestamp, formatAggregate(*bucket)); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(s.buckets, key)
+	}
+	for key, file := range s.files {
+		if err := file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(s.files, key)
+	}
+	return firstErr
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func formatAggregate(a Aggregate) string {
+	return fmt.Sprintf("%d %s %s %s %d\n", a.Timestamp.UnixMilli(),
+		formatValue(a.Min), formatValue(a.Max), formatValue(a.Avg), a.Count)
+}
+
+// parseLine lê uma linha "ms valor" (raw) ou "ms min max avg count"
+func parseLine(res Resolution, line string) (Aggregate, bool) {
+	fields := strings.Fields(line)
+	expected := 5
+	if res == ResolutionRaw {
+		expected = 2
+	}
+	if len(fields) != expected {
+		return Aggregate{}, false
+	}
+
+	ms, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return Aggregate{}, false
+	}
+	values := make([]float64, len(fields)-1)
+	for i, f := range fields[1:] {
+		if values[i], err = strconv.ParseFloat(f, 64); err != nil {
+			return Aggregate{}, false
+		}
+	}
+
+	a := Aggregate{Timestamp: time.UnixMilli(ms).UTC()}
+	if res == ResolutionRaw {
+		a.Min, a.Max, a.Avg, a.Count = values[0], values[0], values[0], 1
+	} else {
+		a.Min, a.Max, a.Avg, a.Count = values[0], values[1], values[2], int(values[3])
+	}
+	return a, true
+}
@@ -0,0 +1,211 @@
+package metrics
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T, dir string) *Store {
+	t.Helper()
+	store, err := OpenStore(dir, DefaultStoreOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func appendPoints(t *testing.T, store *Store, metric string, base time.Time, points map[time.Duration]float64, order []time.Duration) {
+	t.Helper()
+	for _, offset := range order {
+		if err := store.Append(metric, MetricPoint{Timestamp: base.Add(offset), Value: points[offset]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func query(t *testing.T, store *Store, metric string, base time.Time, res Resolution) []Aggregate {
+	t.Helper()
+	points, err := store.Query(metric, base, base.Add(time.Hour), res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return points
+}
+
+// testHour é uma hora já encerrada, dentro da retenção dos dados brutos
+func testHour() time.Time {
+	return time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+}
+
+func TestStoreRollups(t *testing.T) {
+	store := openTestStore(t, t.TempDir())
+	defer store.Close()
+
+	base := testHour()
+	values := map[time.Duration]float64{0: 10, 30 * time.Second: 20, 61 * time.Second: 30, 90 * time.Second: 40}
+	appendPoints(t, store, "cpu_usage", base, values, []time.Duration{0, 30 * time.Second, 61 * time.Second, 90 * time.Second})
+
+	if raw := query(t, store, "cpu_usage", base, ResolutionRaw); len(raw) != 4 || raw[3].Avg != 40 {
+		t.Errorf("pontos brutos = %+v", raw)
+	}
+
+	minutes := query(t, store, "cpu_usage", base, ResolutionMinute)
+	if len(minutes) != 2 {
+		t.Fatalf("agregados por minuto = %+v", minutes)
+	}
+	want := Aggregate{Timestamp: base, Min: 10, Max: 20, Avg: 15, Count: 2}
+	if minutes[0] != want {
+		t.Errorf("primeiro minuto = %+v, quer %+v", minutes[0], want)
+	}
+	// O minuto em andamento também aparece na consulta
+	if minutes[1].Count != 2 || minutes[1].Avg != 35 {
+		t.Errorf("minuto em andamento = %+v", minutes[1])
+	}
+
+	hours := query(t, store, "cpu_usage", base, ResolutionHour)
+	if len(hours) != 1 || hours[0].Count != 4 || hours[0].Avg != 25 || hours[0].Min != 10 || hours[0].Max != 40 {
+		t.Errorf("agregado por hora = %+v", hours)
+	}
+}
+
+func TestStoreCloseFlushesRollups(t *testing.T) {
+	dir := t.TempDir()
+	base := testHour()
+
+	store := openTestStore(t, dir)
+	appendPoints(t, store, "cpu_usage", base, map[time.Duration]float64{0: 10, time.Minute: 30}, []time.Duration{0, time.Minute})
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reabrir (mais de uma vez) não duplica os agregados gravados no Close
+	for i := 0; i < 2; i++ {
+		store = openTestStore(t, dir)
+		hours := query(t, store, "cpu_usage", base, ResolutionHour)
+		if len(hours) != 1 || hours[0].Count != 2 || hours[0].Avg != 20 {
+			t.Fatalf("abertura %d: agregado por hora = %+v", i, hours)
+		}
+		if minutes := query(t, store, "cpu_usage", base, ResolutionMinute); len(minutes) != 2 {
+			t.Fatalf("abertura %d: agregados por minuto = %+v", i, minutes)
+		}
+		if err := store.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, string(ResolutionHour), "cpu_usage", base.Format(dayLayout)+".log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 1 {
+		t.Errorf("arquivo por hora com %d linhas, quer 1:\n%s", lines, data)
+	}
+}
+
+func TestStoreRecoversAfterCrash(t *testing.T) {
+	dir := t.TempDir()
+	base := testHour()
+
+	// Sem Close, como em uma queda do processo
+	crashed := openTestStore(t, dir)
+	appendPoints(t, crashed, "cpu_usage", base, map[time.Duration]float64{0: 10, time.Second: 20, 2 * time.Second: 30}, []time.Duration{0, time.Second, 2 * time.Second})
+
+	store := openTestStore(t, dir)
+	defer store.Close()
+	hours := query(t, store, "cpu_usage", base, ResolutionHour)
+	if len(hours) != 1 || hours[0].Count != 3 || hours[0].Avg != 20 {
+		t.Errorf("agregado por hora reconstruído = %+v", hours)
+	}
+	minutes := query(t, store, "cpu_usage", base, ResolutionMinute)
+	if len(minutes) != 1 || minutes[0].Count != 3 {
+		t.Errorf("agregado por minuto reconstruído = %+v", minutes)
+	}
+}
+
+func TestStoreTruncatesTornLine(t *testing.T) {
+	dir := t.TempDir()
+	base := testHour()
+
+	crashed := openTestStore(t, dir)
+	appendPoints(t, crashed, "cpu_usage", base, map[time.Duration]float64{0: 10, time.Second: 20}, []time.Duration{0, time.Second})
+
+	// A queda interrompeu a escrita de "<ts> 40" no meio do valor
+	filename := crashed.filename(ResolutionRaw, "cpu_usage", base)
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fmt.Fprintf(file, "%d 4", base.Add(2*time.Second).UnixMilli()); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	store := openTestStore(t, dir)
+	defer store.Close()
+	if raw := query(t, store, "cpu_usage", base, ResolutionRaw); len(raw) != 2 {
+		t.Errorf("pontos brutos antes da escrita = %+v, quer 2", raw)
+	}
+
+	appendPoints(t, store, "cpu_usage", base, map[time.Duration]float64{3 * time.Second: 50}, []time.Duration{3 * time.Second})
+	var values []float64
+	for _, p := range query(t, store, "cpu_usage", base, ResolutionRaw) {
+		values = append(values, p.Avg)
+	}
+	if fmt.Sprint(values) != "[10 20 50]" {
+		t.Errorf("pontos brutos = %v, quer [10 20 50]", values)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"); len(lines) != 3 {
+		t.Errorf("arquivo com %d linhas, quer 3:\n%s", len(lines), data)
+	}
+}
+
+func TestStoreRejectsInvalidNames(t *testing.T) {
+	store := openTestStore(t, t.TempDir())
+	defer store.Close()
+
+	if err := store.Append("../cpu", MetricPoint{Timestamp: time.Now(), Value: 1}); err == nil {
+		t.Error("Append aceitou nome com caminho")
+	}
+	if _, err := store.Query("cpu_usage", time.Now().Add(-time.Hour), time.Now(), "5m"); err == nil {
+		t.Error("Query aceitou resolução inválida")
+	}
+	if _, err := OpenStore(t.TempDir(), StoreOptions{RawRetention: time.Minute}); err == nil {
+		t.Error("OpenStore aceitou retenção bruta menor que 1h")
+	}
+}
+
+func TestStoreApplyRetention(t *testing.T) {
+	dir := t.TempDir()
+	store := openTestStore(t, dir)
+	defer store.Close()
+
+	now := time.Now().UTC()
+	old := now.Add(-5 * 24 * time.Hour)
+	for _, ts := range []time.Time{old, now} {
+		if err := store.Append("cpu_usage", MetricPoint{Timestamp: ts, Value: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.ApplyRetention(now); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(store.filename(ResolutionRaw, "cpu_usage", old)); !os.IsNotExist(err) {
+		t.Errorf("arquivo bruto antigo não foi removido: %v", err)
+	}
+	if _, err := os.Stat(store.filename(ResolutionRaw, "cpu_usage", now)); err != nil {
+		t.Errorf("arquivo bruto atual removido: %v", err)
+	}
+	if _, err := os.Stat(store.filename(ResolutionMinute, "cpu_usage", old)); err != nil {
+		t.Errorf("agregado por minuto dentro da retenção removido: %v", err)
+	}
+}
@@ -1,52 +1,247 @@
 package model
 
+import "time"
+
 // MachineInfo representa as informações coletadas da máquina
 type MachineInfo struct {
-	OS            string
-	Hostname      string
-	Processor     ProcessorInfo
-	BIOS          BIOSInfo
-	Memory        []MemoryInfo
-	HDs           []HDInfo
-	USBDevices    []USBDevice
-	MotherboardSN string
-	SerialNumber  string
+	OS            string            `json:"os"`
+	OSDetails     OSInfo            `json:"os_details"`
+	Hostname      string            `json:"hostname"`
+	Processor     ProcessorInfo     `json:"processor"`
+	BIOS          BIOSInfo          `json:"bios"`
+	Memory        []MemoryInfo      `json:"memory"`
+	HDs           []HDInfo          `json:"disks"`
+	DiskHealth    []DiskHealth      `json:"disk_health"`
+	USBDevices    []USBDevice       `json:"usb_devices"`
+	Network       NetworkInfo       `json:"network"`
+	GPUs          []GPUInfo         `json:"gpus"`
+	Monitors      []MonitorInfo     `json:"monitors"`
+	PCIDevices    []PCIDevice       `json:"pci_devices"`
+	Software      []SoftwarePackage `json:"software"`
+	MotherboardSN string            `json:"motherboard_serial"`
+	SerialNumber  string            `json:"serial_number"`
+	// Extra guarda os resultados de coletores adicionais, pelo nome do coletor
+	Extra map[string]any `json:"extra,omitempty"`
+	// CollectorErrors lista os coletores que falharam e o motivo
+	CollectorErrors map[string]string `json:"collector_errors,omitempty"`
+}
+
+// Estados do Secure Boot
+const (
+	SecureBootEnabled  = "enabled"
+	SecureBootDisabled = "disabled"
+	// SecureBootUnsupported indica firmware BIOS legado, sem UEFI
+	SecureBootUnsupported = "unsupported"
+)
+
+// OSInfo representa os detalhes do sistema operacional
+type OSInfo struct {
+	// Name e Version são o nome e a versão da distribuição, e ID e
+	// VersionID as formas para comparação (ex.: "ubuntu", "22.04")
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	ID        string `json:"id"`
+	VersionID string `json:"version_id"`
+	Kernel    string `json:"kernel"`
+	// Arch é a arquitetura da máquina (ex.: "x86_64", "aarch64")
+	Arch          string    `json:"arch"`
+	BootTime      time.Time `json:"boot_time"`
+	UptimeSeconds uint64    `json:"uptime_seconds"`
+	// Timezone é o fuso horário do sistema (ex.: "America/Sao_Paulo")
+	Timezone string `json:"timezone"`
+	Locale   string `json:"locale"`
+	// Virtualization é o hipervisor quando a máquina é virtual (ex.:
+	// "kvm", "vmware") e Container o ambiente de contêiner (ex.: "docker");
+	// ambos ficam vazios em máquinas físicas
+	Virtualization string `json:"virtualization"`
+	Container      string `json:"container"`
+	// SecureBoot é enabled, disabled, unsupported ou vazio quando não
+	// foi possível determinar
+	SecureBoot string `json:"secure_boot"`
 }
 
 // ProcessorInfo representa as informações do processador
 type ProcessorInfo struct {
-	Model        string
-	Cores        int
-	Threads      int
-	FrequencyGHz float64
+	Model        string  `json:"model"`
+	Cores        int     `json:"cores"`
+	Threads      int     `json:"threads"`
+	FrequencyGHz float64 `json:"frequency_ghz"`
 }
 
 // BIOSInfo representa as informações da BIOS
 type BIOSInfo struct {
-	Vendor      string
-	Version     string
-	ReleaseDate string
+	Vendor      string `json:"vendor"`
+	Version     string `json:"version"`
+	ReleaseDate string `json:"release_date"`
 }
 
 // MemoryInfo representa as informações de memória
 type MemoryInfo struct {
-	Slot         string
-	SizeMB       uint64
-	Manufacturer string
-	SerialNumber string
+	Slot         string `json:"slot"`
+	SizeMB       uint64 `json:"size_mb"`
+	Manufacturer string `json:"manufacturer"`
+	SerialNumber string `json:"serial_number"`
 }
 
-// HDInfo representa as informações do disco rígido
+// HDInfo representa as informações de um disco
 type HDInfo struct {
-	Model  string
-	Serial string
-	SizeGB uint64
+	// Name é o dispositivo no sistema (ex.: "sda", "nvme0n1")
+	Name   string `json:"name"`
+	Model  string `json:"model"`
+	Serial string `json:"serial"`
+	// SizeGB é a capacidade em GiB inteiros, para exibição, e SizeBytes
+	// a capacidade exata
+	SizeGB    uint64 `json:"size_gb"`
+	SizeBytes uint64 `json:"size_bytes"`
+	// Controller é o barramento do disco: ide, scsi, nvme, virtio ou mmc
+	Controller string          `json:"controller"`
+	Removable  bool            `json:"removable"`
+	Rotational bool            `json:"rotational"`
+	NVMe       bool            `json:"nvme"`
+	Partitions []PartitionInfo `json:"partitions"`
+}
+
+// PartitionInfo representa uma partição de disco e o uso do sistema de
+// arquivos montado nela
+type PartitionInfo struct {
+	Name string `json:"name"`
+	// Label é o rótulo do sistema de arquivos ou, na falta dele, o nome da
+	// partição na tabela GPT
+	Label      string `json:"label"`
+	Filesystem string `json:"filesystem"`
+	// MountPoint é vazio quando a partição não está montada
+	MountPoint string `json:"mount_point"`
+	SizeBytes  uint64 `json:"size_bytes"`
+	// UsedBytes e FreeBytes só são preenchidos para partições montadas
+	UsedBytes uint64 `json:"used_bytes"`
+	FreeBytes uint64 `json:"free_bytes"`
+	ReadOnly  bool   `json:"read_only"`
+}
+
+// DiskHealth representa a saúde de um disco, lida dos atributos SMART ou
+// do log de saúde do NVMe. Os contadores que não se aplicam ao tipo do
+// disco ficam zerados.
+type DiskHealth struct {
+	// Device é o dispositivo no sistema (ex.: "sda", "nvme0")
+	Device string `json:"device"`
+	Model  string `json:"model"`
+	Serial string `json:"serial"`
+	// Passed é a avaliação geral do disco; false indica falha iminente
+	Passed       bool   `json:"passed"`
+	PowerOnHours uint64 `json:"power_on_hours"`
+	TemperatureC int    `json:"temperature_c"`
+	// ReallocatedSectors e PendingSectors são os setores já realocados e
+	// os aguardando realocação (atributos SMART 5 e 197)
+	ReallocatedSectors uint64 `json:"reallocated_sectors"`
+	PendingSectors     uint64 `json:"pending_sectors"`
+	// MediaErrors são os erros de integridade de dados do NVMe
+	MediaErrors uint64 `json:"media_errors"`
+	// PercentageUsed é a estimativa de desgaste do NVMe; pode passar de 100
+	PercentageUsed int `json:"percentage_used"`
 }
 
 // USBDevice representa as informações de um dispositivo USB
 type USBDevice struct {
-	VendorID  string
-	ProductID string
-	Name      string
-	Serial    string
+	VendorID  string `json:"vendor_id"`
+	ProductID string `json:"product_id"`
+	Name      string `json:"name"`
+	Serial    string `json:"serial"`
+	// Port é o caminho físico da porta no formato do sysfs (ex.: "1-2.3")
+	Port string `json:"port"`
+	// InterfaceClasses são as classes das interfaces em hexadecimal (ex.: "08" para armazenamento)
+	InterfaceClasses []string `json:"interface_classes,omitempty"`
+}
+
+// GPUInfo representa as informações de uma placa de vídeo
+type GPUInfo struct {
+	Vendor     string `json:"vendor"`
+	Model      string `json:"model"`
+	PCIAddress string `json:"pci_address"`
+	Driver     string `json:"driver"`
+	// VRAMMB é a memória de vídeo dedicada (zero quando o driver não a informa)
+	VRAMMB uint64 `json:"vram_mb,omitempty"`
+}
+
+// MonitorInfo representa um monitor conectado, lido do EDID
+type MonitorInfo struct {
+	// Connector é a saída de vídeo (ex.: "HDMI-A-1", "eDP-1")
+	Connector    string `json:"connector"`
+	Manufacturer string `json:"manufacturer"`
+	Model        string `json:"model"`
+	Serial       string `json:"serial"`
+	// Width e Height são a resolução nativa, em pixels
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// PCIDevice representa um dispositivo PCI
+type PCIDevice struct {
+	// Address é o endereço no barramento (ex.: "0000:03:00.0")
+	Address string `json:"address"`
+	// ClassID é a classe e a subclasse em hexadecimal (ex.: "0200" para
+	// controladora Ethernet) e Class o seu nome
+	ClassID   string `json:"class_id"`
+	Class     string `json:"class"`
+	VendorID  string `json:"vendor_id"`
+	Vendor    string `json:"vendor"`
+	ProductID string `json:"product_id"`
+	Product   string `json:"product"`
+	// Subsystem identifica a placa montada pelo fabricante (ex.: "1028:0a5f")
+	Subsystem string `json:"subsystem"`
+	// Driver é o driver do kernel associado (vazio se nenhum)
+	Driver string `json:"driver"`
+}
+
+// Origens dos pacotes de software
+const (
+	SoftwareDpkg    = "dpkg"
+	SoftwareRPM     = "rpm"
+	SoftwareFlatpak = "flatpak"
+	SoftwareSnap    = "snap"
+)
+
+// SoftwarePackage representa um programa instalado
+type SoftwarePackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Arch    string `json:"arch"`
+	// Vendor é o fornecedor ou, na falta dele, o mantenedor do pacote
+	Vendor string `json:"vendor"`
+	// InstallDate é a data da instalação ou da última atualização (zero
+	// quando desconhecida)
+	InstallDate time.Time `json:"install_date"`
+	// Source é o gerenciador que instalou o pacote: dpkg, rpm, flatpak ou snap
+	Source string `json:"source"`
+}
+
+// Tipos de interface de rede
+const (
+	InterfaceWired    = "wired"
+	InterfaceWireless = "wireless"
+	InterfaceVirtual  = "virtual"
+)
+
+// NetworkInfo representa a configuração de rede da máquina
+type NetworkInfo struct {
+	Interfaces []NetworkInterface `json:"interfaces"`
+	// DNSServers são os servidores DNS configurados no sistema
+	DNSServers []string `json:"dns_servers"`
+}
+
+// NetworkInterface representa as informações de uma interface de rede
+type NetworkInterface struct {
+	Name string `json:"name"`
+	MAC  string `json:"mac"`
+	// IPv4 e IPv6 são os endereços no formato CIDR (ex.: "192.168.0.10/24")
+	IPv4 []string `json:"ipv4"`
+	IPv6 []string `json:"ipv6"`
+	// Gateways são os gateways padrão que saem por esta interface
+	Gateways []string `json:"gateways,omitempty"`
+	// SpeedMbps é a velocidade do link (zero quando desconhecida ou sem link)
+	SpeedMbps int    `json:"speed_mbps"`
+	MTU       int    `json:"mtu"`
+	Up        bool   `json:"up"`
+	Driver    string `json:"driver"`
+	// Type é wired, wireless ou virtual
+	Type string `json:"type"`
 }
@@ -1,30 +1,233 @@
 package service
 
 import (
-	"runtime"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/dev/falcon-agent/internal/api"
 	"github.com/dev/falcon-agent/internal/config"
+	"github.com/dev/falcon-agent/internal/diskhealth"
+	"github.com/dev/falcon-agent/internal/export"
+	"github.com/dev/falcon-agent/internal/fleet"
+	"github.com/dev/falcon-agent/internal/inventory"
+	"github.com/dev/falcon-agent/internal/metrics"
+	"github.com/dev/falcon-agent/internal/model"
+	"github.com/dev/falcon-agent/internal/spool"
+	"github.com/dev/falcon-agent/internal/systemd"
+	"github.com/dev/falcon-agent/internal/usb"
 	"github.com/dev/falcon-agent/pkg/logger"
 )
 
+// retentionInterval é o intervalo entre as limpezas de métricas antigas
+const retentionInterval = time.Hour
+
+// enrollmentFile é o arquivo, em Config.DataPath, com o registro no servidor
+const enrollmentFile = "enrollment.json"
+
+// usbAuditFile é o arquivo, em Config.DataPath, com as decisões da política USB
+const usbAuditFile = "usb-audit.log"
+
+// inventoryHistoryFile e inventorySnapshotFile são os arquivos, em
+// Config.DataPath, com as mudanças do inventário e o último inventário coletado
+const (
+	inventoryHistoryFile  = "inventory-changes.log"
+	inventorySnapshotFile = "inventory.json"
+)
+
+// diskHealthDir é o diretório, em Config.DataPath, do histórico de saúde
+// dos discos
+const diskHealthDir = "disk-health"
+
+// spoolDir é o diretório, em Config.DataPath, da fila de relatórios pendentes
+const spoolDir = "spool"
+
 // Agent representa o serviço principal do agente
 type Agent struct {
-	config *config.Config
+	// config é trocada por inteiro quando a configuração é recarregada
+	config atomic.Pointer[config.Config]
 	logger logger.Logger
+
+	mu          sync.RWMutex
+	machineInfo *model.MachineInfo
+	metrics     *metrics.SystemMetrics
+	store       *metrics.Store
+	lastIO      ioCounters
+	// lastProcs só é acessado pela goroutine principal
+	lastProcs processCounters
+	processes []metrics.ProcessSample
+
+	// baseline é o inventário usado na detecção de mudanças; só é acessado
+	// pela goroutine principal
+	baseline         *model.MachineInfo
+	inventoryHistory *inventory.History
+	diskHealth       *diskhealth.Store
+
+	api  *api.Server
+	stop chan struct{}
+	done chan struct{}
+	// reconfigure avisa o loop principal que os intervalos mudaram
+	reconfigure chan struct{}
+
+	// ctx, cancel e workers controlam as rotinas em segundo plano
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+
+	// reloadMu serializa as recargas de configuração
+	reloadMu sync.Mutex
+	// collectorDefaults são as opções dos coletores antes da configuração
+	collectorDefaults map[string]CollectorOptions
+
+	fleetMu     sync.Mutex
+	reporter    *fleet.Reporter
+	fleetCancel context.CancelFunc
+	fleetDone   chan struct{}
+
+	usbWatcher     *usb.Watcher
+	usbDone        chan struct{}
+	usbPolicy      *usb.Enforcer
+	usbAudit       *usb.AuditLog
+	handlersMu     sync.RWMutex
+	usbHandlers    []func(usb.Event)
+	changeHandlers []func([]inventory.Change)
+	healthHandlers []func([]diskhealth.Event)
 }
 
 // New cria uma nova instância do agente
 func New(cfg *config.Config, log logger.Logger) *Agent {
-	return &Agent{
-		config: cfg,
-		logger: log,
+	a := &Agent{
+		logger:      log,
+		metrics:     metrics.NewSystemMetrics(),
+		reconfigure: make(chan struct{}, 1),
 	}
+	a.config.Store(cfg)
+	return a
+}
+
+// Config retorna a configuração em vigor
+func (a *Agent) Config() *config.Config {
+	return a.config.Load()
 }
 
 // Start inicia o agente
 func (a *Agent) Start() error {
-	a.logger.Info("Iniciando Falcon Agent na plataforma: %s", a.config.Platform)
+	a.logger.Info("Iniciando Falcon Agent na plataforma: %s", a.Config().Platform)
+
+	// Sem o armazenamento em disco o agente segue apenas com o histórico em memória
+	store, err := metrics.OpenStore(filepath.Join(a.Config().DataPath, "metrics"), a.Config().MetricsRetention)
+	if err != nil {
+		a.logger.Error("Erro ao abrir o armazenamento de métricas: %v", err)
+	} else {
+		a.store = store
+		a.metrics.AttachStore(store)
+		if err := store.ApplyRetention(time.Now()); err != nil {
+			a.logger.Error("Erro ao aplicar retenção de métricas: %v", err)
+		}
+	}
+
+	a.applyCollectors(a.Config())
+
+	// O último inventário salvo permite detectar mudanças feitas com o
+	// agente parado
+	history, err := inventory.OpenHistory(filepath.Join(a.Config().DataPath, inventoryHistoryFile))
+	if err != nil {
+		a.logger.Error("Erro ao abrir o histórico de inventário: %v", err)
+	} else {
+		a.inventoryHistory = history
+	}
+	diskHealth, err := diskhealth.OpenStore(filepath.Join(a.Config().DataPath, diskHealthDir))
+	if err != nil {
+		a.logger.Error("Erro ao abrir o histórico de saúde dos discos: %v", err)
+	} else {
+		a.diskHealth = diskHealth
+	}
+	baseline, err := inventory.LoadSnapshot(filepath.Join(a.Config().DataPath, inventorySnapshotFile))
+	if err != nil && !os.IsNotExist(err) {
+		a.logger.Error("Erro ao carregar o último inventário: %v", err)
+	}
+	a.baseline = baseline
+
+	// Coleta inicial para que a API e a interface já tenham dados
+	a.refreshMachineInfo()
+
+	if a.Config().APIAddr != "" {
+		a.api = api.New(a.Config().APIAddr, a, a.logger.With("component", "api"))
+		if err := a.api.Start(); err != nil {
+			return err
+		}
+	}
+
+	policy, err := loadUSBPolicy(a.Config().USBPolicyPath)
+	if err != nil {
+		a.logger.Error("Erro ao carregar política USB: %v", err)
+	} else if policy == nil {
+		a.logger.Info("Nenhuma política USB em %s; dispositivos apenas monitorados", a.Config().USBPolicyPath)
+	} else {
+		a.setUSBPolicy(policy)
+	}
+
+	// Sem o monitoramento de hotplug a lista de USB só é atualizada na
+	// próxima coleta completa do inventário
+	watcher, err := usb.NewWatcher()
+	if err != nil {
+		a.logger.Error("Erro ao iniciar o monitoramento de USB: %v", err)
+	} else {
+		a.usbWatcher = watcher
+		watcher.OnHubAttached(func(dir string) {
+			if enforcer := a.usbEnforcer(); enforcer != nil {
+				if err := enforcer.AuthorizeHub(dir); err != nil {
+					a.logger.Error("Erro ao autorizar hub USB: %v", err)
+				}
+			}
+		})
+		a.usbDone = make(chan struct{})
+		go func() {
+			defer close(a.usbDone)
+			a.usbLoop(watcher)
+		}()
+	}
+
+	a.primeSampler()
+
+	a.ctx, a.cancel = context.WithCancel(context.Background())
+
+	// Os eventos USB e de saúde dos discos vão para o envio ao servidor em
+	// vigor, que pode ser trocado quando a configuração é recarregada
+	a.OnUSBEvent(func(event usb.Event) {
+		a.fleetMu.Lock()
+		reporter := a.reporter
+		a.fleetMu.Unlock()
+		if reporter != nil {
+			reporter.HandleUSBEvent(event)
+		}
+	})
+	a.OnDiskHealthEvent(func(events []diskhealth.Event) {
+		a.fleetMu.Lock()
+		reporter := a.reporter
+		a.fleetMu.Unlock()
+		if reporter != nil {
+			reporter.HandleDiskHealthEvents(events)
+		}
+	})
+	if a.Config().ServerURL != "" {
+		if err := a.startFleetReporter(); err != nil {
+			a.logger.Error("Erro ao iniciar o envio ao servidor: %v", err)
+		}
+	}
+
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		a.watchConfig(a.ctx)
+	}()
+
+	a.stop = make(chan struct{})
+	a.done = make(chan struct{})
 
 	// Inicia o loop principal do agente
 	go a.mainLoop()
@@ -35,27 +238,525 @@ func (a *Agent) Start() error {
 // Stop para o agente
 func (a *Agent) Stop() error {
 	a.logger.Info("Parando Falcon Agent...")
+
+	if a.stop != nil {
+		close(a.stop)
+		<-a.done
+	}
+
+	if a.cancel != nil {
+		a.cancel()
+		a.workers.Wait()
+	}
+
+	// Uma recarga em andamento termina antes de os componentes serem fechados
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	// O evento em andamento ainda pode gravar na auditoria da política USB
+	if a.usbWatcher != nil {
+		a.usbWatcher.Close()
+		<-a.usbDone
+	}
+
+	if a.usbAudit != nil {
+		a.usbAudit.Close()
+	}
+
+	if a.inventoryHistory != nil {
+		a.inventoryHistory.Close()
+	}
+
+	if a.diskHealth != nil {
+		a.diskHealth.Close()
+	}
+
+	if a.store != nil {
+		if err := a.store.Close(); err != nil {
+			a.logger.Error("Erro ao fechar o armazenamento de métricas: %v", err)
+		}
+	}
+
+	if a.api != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := a.api.Stop(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MachineInfo retorna o inventário coletado mais recente
+func (a *Agent) MachineInfo() *model.MachineInfo {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.machineInfo
+}
+
+// Metrics retorna o histórico de métricas do sistema
+func (a *Agent) Metrics() *metrics.SystemMetrics {
+	return a.metrics
+}
+
+// Processes retorna os processos da última amostra, na ordem do sistema
+func (a *Agent) Processes() []metrics.ProcessSample {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	processes := make([]metrics.ProcessSample, len(a.processes))
+	copy(processes, a.processes)
+	return processes
+}
+
+// MetricStore retorna o armazenamento de métricas em disco, ou nil se ele
+// não pôde ser aberto
+func (a *Agent) MetricStore() *metrics.Store {
+	return a.store
+}
+
+// ExportInventory grava o inventário atual no diretório e formato
+// configurados e retorna o arquivo criado
+func (a *Agent) ExportInventory() (string, error) {
+	filename, err := export.ExportInventory(a.MachineInfo(), a.Config().ExportFormat, a.Config().ExportDir)
+	if err != nil {
+		return "", err
+	}
+	a.logger.Info("Inventário exportado para %s", filename)
+	return filename, nil
+}
+
+// CheckCollectors verifica se os coletores citados em collectors.disabled
+// e collectors.timeouts existem. Os nomes dependem dos coletores
+// registrados neste pacote, por isso a verificação fica fora de
+// config.Validate e é feita logo após config.Load e a cada recarga.
+func CheckCollectors(cfg *config.Config) error {
+	registry := DefaultRegistry()
+	for _, name := range cfg.DisabledCollectors {
+		if _, ok := registry.Options(name); !ok {
+			return &config.KeyError{Key: "collectors.disabled", Err: fmt.Errorf("coletor desconhecido: %s", name)}
+		}
+	}
+	for name := range cfg.CollectorTimeouts {
+		if _, ok := registry.Options(name); !ok {
+			return &config.KeyError{Key: "collectors.timeouts." + name, Err: fmt.Errorf("coletor desconhecido: %s", name)}
+		}
+	}
+	return nil
+}
+
+// applyCollectors aplica aos coletores de inventário as opções
+// collectors.disabled e collectors.timeouts. Coletores que não aparecem na
+// configuração voltam às opções com que foram registrados.
+func (a *Agent) applyCollectors(cfg *config.Config) {
+	registry := DefaultRegistry()
+	if a.collectorDefaults == nil {
+		a.collectorDefaults = make(map[string]CollectorOptions)
+		for _, name := range registry.Names() {
+			a.collectorDefaults[name], _ = registry.Options(name)
+		}
+	}
+
+	disabled := make(map[string]bool)
+	for _, name := range cfg.DisabledCollectors {
+		disabled[name] = true
+	}
+	for name, defaults := range a.collectorDefaults {
+		registry.SetEnabled(name, defaults.Enabled && !disabled[name])
+		timeout, ok := cfg.CollectorTimeouts[name]
+		if !ok {
+			timeout = defaults.Timeout
+		}
+		registry.SetTimeout(name, timeout)
+	}
+	setDiskFilter(cfg.Disks)
+	if len(disabled) > 0 {
+		a.logger.Info("Coletores desativados: %v", cfg.DisabledCollectors)
+	}
+}
+
+// InventoryChanges retorna as últimas mudanças do inventário registradas
+func (a *Agent) InventoryChanges(limit int) ([]inventory.Change, error) {
+	if a.inventoryHistory == nil {
+		return nil, fmt.Errorf("histórico de inventário indisponível")
+	}
+	return a.inventoryHistory.Recent(limit)
+}
+
+// OnInventoryChange registra uma função chamada quando uma coleta do
+// inventário encontra mudanças de hardware. As funções são chamadas na
+// goroutine principal do agente e não devem bloquear.
+func (a *Agent) OnInventoryChange(handler func([]inventory.Change)) {
+	a.handlersMu.Lock()
+	defer a.handlersMu.Unlock()
+	a.changeHandlers = append(a.changeHandlers, handler)
+}
+
+// DiskHealthHistory retorna as leituras de saúde gravadas de um disco
+func (a *Agent) DiskHealthHistory(serial string) ([]diskhealth.Sample, error) {
+	if a.diskHealth == nil {
+		return nil, fmt.Errorf("histórico de saúde dos discos indisponível")
+	}
+	return a.diskHealth.History(serial)
+}
+
+// DiskHealthEvents retorna os últimos avisos de piora na saúde dos discos
+func (a *Agent) DiskHealthEvents(limit int) ([]diskhealth.Event, error) {
+	if a.diskHealth == nil {
+		return nil, fmt.Errorf("histórico de saúde dos discos indisponível")
+	}
+	return a.diskHealth.Events(limit)
+}
+
+// OnDiskHealthEvent registra uma função chamada quando a saúde de algum
+// disco piora. As funções são chamadas na goroutine principal do agente e
+// não devem bloquear.
+func (a *Agent) OnDiskHealthEvent(handler func([]diskhealth.Event)) {
+	a.handlersMu.Lock()
+	defer a.handlersMu.Unlock()
+	a.healthHandlers = append(a.healthHandlers, handler)
+}
+
+// startFleetReporter inicia o registro e o envio periódico ao servidor
+func (a *Agent) startFleetReporter() error {
+	cfg := a.Config()
+	client, err := fleet.NewClient(fleet.ClientConfig{
+		ServerURL:       cfg.ServerURL,
+		EnrollmentToken: cfg.EnrollmentToken,
+		StatePath:       filepath.Join(cfg.DataPath, enrollmentFile),
+	})
+	if err != nil {
+		return err
+	}
+
+	queue, err := spool.Open(filepath.Join(cfg.DataPath, spoolDir), spool.Options{MaxBytes: cfg.SpoolMaxBytes})
+	if err != nil {
+		return err
+	}
+	if n := queue.Len(); n > 0 {
+		a.logger.Info("%d relatórios pendentes na fila de envio", n)
+	}
+
+	reporter := fleet.NewReporter(client, queue, a, cfg.ReportInterval, a.logger.With("component", "fleet"))
+	ctx, cancel := context.WithCancel(a.ctx)
+	done := make(chan struct{})
+
+	a.fleetMu.Lock()
+	a.reporter = reporter
+	a.fleetCancel = cancel
+	a.fleetDone = done
+	a.fleetMu.Unlock()
+
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		defer close(done)
+		reporter.Run(ctx)
+	}()
+
+	a.logger.Info("Enviando inventário e métricas para %s a cada %v", cfg.ServerURL, cfg.ReportInterval)
 	return nil
 }
 
+// stopFleetReporter encerra o envio ao servidor, se estiver ativo. Os
+// relatórios pendentes continuam na fila em disco.
+func (a *Agent) stopFleetReporter() {
+	a.fleetMu.Lock()
+	cancel, done := a.fleetCancel, a.fleetDone
+	a.reporter, a.fleetCancel, a.fleetDone = nil, nil, nil
+	a.fleetMu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// OnUSBEvent registra uma função chamada a cada conexão ou desconexão de
+// dispositivo USB. As funções são chamadas na goroutine de monitoramento e
+// não devem bloquear.
+func (a *Agent) OnUSBEvent(handler func(usb.Event)) {
+	a.handlersMu.Lock()
+	defer a.handlersMu.Unlock()
+	a.usbHandlers = append(a.usbHandlers, handler)
+}
+
+// usbLoop processa os eventos de hotplug até o watcher ser encerrado
+func (a *Agent) usbLoop(watcher *usb.Watcher) {
+	for event := range watcher.Events() {
+		switch event.Type {
+		case usb.DeviceAttached:
+			a.usbLogger(event.Device).Info("Dispositivo USB conectado: %s (%s:%s) na porta %s",
+				event.Device.Name, event.Device.VendorID, event.Device.ProductID, event.Port)
+		case usb.DeviceDetached:
+			a.usbLogger(event.Device).Info("Dispositivo USB desconectado: %s (%s:%s) da porta %s",
+				event.Device.Name, event.Device.VendorID, event.Device.ProductID, event.Port)
+		}
+
+		if enforcer := a.usbEnforcer(); event.Type == usb.DeviceAttached && enforcer != nil {
+			decision := a.applyUSBPolicy(enforcer, event.Device)
+			event.Decision = &decision
+		}
+
+		a.applyUSBEvent(event)
+
+		a.handlersMu.RLock()
+		handlers := a.usbHandlers
+		a.handlersMu.RUnlock()
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
+
+// loadUSBPolicy lê a política USB; retorna nil sem erro se o arquivo não existe
+func loadUSBPolicy(path string) (*usb.Policy, error) {
+	policy, err := usb.LoadPolicy(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return policy, err
+}
+
+// setUSBPolicy coloca a política em vigor e a aplica aos dispositivos já
+// conectados. Com policy nil os dispositivos passam a ser apenas
+// monitorados, e os que o agente bloqueou são autorizados novamente.
+func (a *Agent) setUSBPolicy(policy *usb.Policy) {
+	if policy == nil {
+		a.mu.Lock()
+		enforcer := a.usbPolicy
+		a.usbPolicy = nil
+		a.mu.Unlock()
+
+		if enforcer != nil {
+			if err := enforcer.Release(); err != nil {
+				a.logger.Error("Erro ao liberar dispositivos USB bloqueados: %v", err)
+			}
+			a.logger.Info("Política USB removida; dispositivos bloqueados pelo agente foram autorizados novamente")
+		}
+		return
+	}
+
+	enforcer := a.usbEnforcer()
+	if enforcer != nil {
+		if err := enforcer.SetPolicy(policy); err != nil {
+			a.logger.Error("Erro ao configurar a autorização dos barramentos USB: %v", err)
+		}
+	} else {
+		if a.usbAudit == nil {
+			audit, err := usb.OpenAuditLog(filepath.Join(a.Config().DataPath, usbAuditFile))
+			if err != nil {
+				a.logger.Error("Erro ao abrir auditoria USB: %v", err)
+				return
+			}
+			a.usbAudit = audit
+		}
+		var err error
+		enforcer, err = usb.NewEnforcer(policy, a.usbAudit)
+		if err != nil {
+			a.logger.Error("Erro ao configurar a autorização dos barramentos USB: %v", err)
+		}
+		a.mu.Lock()
+		a.usbPolicy = enforcer
+		a.mu.Unlock()
+	}
+	a.logger.Info("Política USB carregada: modo %s, %d regras, padrão %s", policy.Mode, len(policy.Rules), policy.Default)
+
+	if info := a.MachineInfo(); info != nil {
+		for _, dev := range info.USBDevices {
+			a.applyUSBPolicy(enforcer, dev)
+		}
+	}
+}
+
+// usbLogger retorna um logger com os campos do dispositivo, que podem ser
+// filtrados no journal (USB_VENDOR, USB_PRODUCT) e no syslog
+func (a *Agent) usbLogger(dev model.USBDevice) logger.Logger {
+	return a.logger.With("component", "usb", "usb_vendor", dev.VendorID, "usb_product", dev.ProductID, "usb_port", dev.Port)
+}
+
+// usbEnforcer retorna o aplicador da política USB, ou nil sem política
+func (a *Agent) usbEnforcer() *usb.Enforcer {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.usbPolicy
+}
+
+// applyUSBPolicy aplica a política a um dispositivo e registra a decisão no log
+func (a *Agent) applyUSBPolicy(enforcer *usb.Enforcer, dev model.USBDevice) usb.Decision {
+	decision := enforcer.Apply(dev)
+
+	log := a.usbLogger(dev).With("usb_action", string(decision.Action))
+	switch {
+	case decision.Error != "":
+		log.Error("Erro ao aplicar política USB (%s) em %s: %s", decision.Action, dev.Name, decision.Error)
+	case decision.Enforced:
+		log.Info("Política USB: %s %s (%s:%s) na porta %s", decision.Action, dev.Name, dev.VendorID, dev.ProductID, dev.Port)
+	default:
+		log.Info("Política USB (monitor): %s %s (%s:%s) na porta %s", decision.Action, dev.Name, dev.VendorID, dev.ProductID, dev.Port)
+	}
+
+	return decision
+}
+
+// applyUSBEvent atualiza a lista de dispositivos do inventário. Uma nova
+// cópia é criada para não alterar o inventário já entregue a outros leitores.
+func (a *Agent) applyUSBEvent(event usb.Event) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.machineInfo == nil {
+		return
+	}
+
+	devices := make([]model.USBDevice, 0, len(a.machineInfo.USBDevices)+1)
+	for _, dev := range a.machineInfo.USBDevices {
+		if dev.Port != event.Port {
+			devices = append(devices, dev)
+		}
+	}
+	if event.Type == usb.DeviceAttached {
+		devices = append(devices, event.Device)
+	}
+
+	info := *a.machineInfo
+	info.USBDevices = devices
+	a.machineInfo = &info
+}
+
 // mainLoop é o loop principal do agente
 func (a *Agent) mainLoop() {
-	ticker := time.NewTicker(5 * time.Second)
+	defer close(a.done)
+
+	cfg := a.Config()
+	ticker := time.NewTicker(cfg.MetricsInterval)
 	defer ticker.Stop()
 
+	inventoryTicker := time.NewTicker(cfg.InventoryInterval)
+	defer inventoryTicker.Stop()
+
+	retentionTicker := time.NewTicker(retentionInterval)
+	defer retentionTicker.Stop()
+
+	// Sob o systemd com WatchdogSec, avisa que o loop continua vivo
+	// na metade do intervalo configurado
+	var watchdog <-chan time.Time
+	if interval := systemd.WatchdogInterval(); interval > 0 {
+		watchdogTicker := time.NewTicker(interval / 2)
+		defer watchdogTicker.Stop()
+		watchdog = watchdogTicker.C
+	}
+
 	for {
 		select {
 		case <-ticker.C:
-			a.collectMetrics()
+			a.sampleMetrics()
+		case <-inventoryTicker.C:
+			a.refreshMachineInfo()
+		case now := <-retentionTicker.C:
+			if a.store != nil {
+				if err := a.store.ApplyRetention(now); err != nil {
+					a.logger.Error("Erro ao aplicar retenção de métricas: %v", err)
+				}
+			}
+		case <-watchdog:
+			if _, err := systemd.Notify(systemd.Watchdog); err != nil {
+				a.logger.Error("Erro ao notificar o watchdog do systemd: %v", err)
+			}
+		case <-a.reconfigure:
+			newCfg := a.Config()
+			if newCfg.MetricsInterval != cfg.MetricsInterval {
+				ticker.Reset(newCfg.MetricsInterval)
+			}
+			if newCfg.InventoryInterval != cfg.InventoryInterval {
+				inventoryTicker.Reset(newCfg.InventoryInterval)
+			}
+			cfg = newCfg
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+// refreshMachineInfo coleta novamente o inventário da máquina
+func (a *Agent) refreshMachineInfo() {
+	info, err := CollectMachineInfo()
+	if err != nil {
+		a.logger.Error("Erro ao coletar informações da máquina: %v", err)
+		return
+	}
+
+	// Um coletor que continua falhando com o mesmo erro é registrado no
+	// log uma vez, e não a cada coleta
+	previous := a.MachineInfo()
+	for name, msg := range info.CollectorErrors {
+		if previous == nil || previous.CollectorErrors[name] != msg {
+			a.logger.Error("Coletor %s falhou: %s", name, msg)
+		}
+	}
+
+	a.mu.Lock()
+	a.machineInfo = info
+	a.mu.Unlock()
+
+	a.detectInventoryChanges(info)
+	a.recordDiskHealth(info)
+}
+
+// detectInventoryChanges compara o inventário com o anterior, registra as
+// mudanças no histórico e avisa os interessados
+func (a *Agent) detectInventoryChanges(info *model.MachineInfo) {
+	changes := inventory.Diff(a.baseline, info)
+	a.baseline = inventory.Baseline(a.baseline, info)
+
+	if err := inventory.SaveSnapshot(filepath.Join(a.Config().DataPath, inventorySnapshotFile), a.baseline); err != nil {
+		a.logger.Error("Erro ao salvar o inventário: %v", err)
+	}
+
+	if len(changes) == 0 {
+		return
+	}
+
+	for _, change := range changes {
+		a.logger.Info("Mudança no inventário: %s", change.Summary)
+	}
+	if a.inventoryHistory != nil {
+		if err := a.inventoryHistory.Record(changes); err != nil {
+			a.logger.Error("Erro ao gravar o histórico de inventário: %v", err)
 		}
 	}
+
+	a.handlersMu.RLock()
+	handlers := a.changeHandlers
+	a.handlersMu.RUnlock()
+	for _, handler := range handlers {
+		handler(changes)
+	}
 }
 
-// collectMetrics coleta métricas do sistema
-func (a *Agent) collectMetrics() {
-	// Exemplo de coleta de métricas básicas
-	a.logger.Info("Coletando métricas do sistema...")
-	a.logger.Info("Sistema Operacional: %s", runtime.GOOS)
-	a.logger.Info("Arquitetura: %s", runtime.GOARCH)
-	a.logger.Info("Número de CPUs: %d", runtime.NumCPU())
+// recordDiskHealth grava a saúde dos discos no histórico e avisa os
+// interessados quando algum atributo piora
+func (a *Agent) recordDiskHealth(info *model.MachineInfo) {
+	if a.diskHealth == nil || len(info.DiskHealth) == 0 {
+		return
+	}
+
+	events, err := a.diskHealth.Record(time.Now(), info.DiskHealth)
+	if err != nil {
+		a.logger.Error("Erro ao gravar o histórico de saúde dos discos: %v", err)
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	for _, event := range events {
+		a.logger.Warn("Saúde do disco piorou: %s", event.Summary)
+	}
+
+	a.handlersMu.RLock()
+	handlers := a.healthHandlers
+	a.handlersMu.RUnlock()
+	for _, handler := range handlers {
+		handler(events)
+	}
 }
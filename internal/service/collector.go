@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// DefaultCollectorTimeout é o tempo máximo de execução de um coletor
+// quando nenhum timeout é informado no registro
+const DefaultCollectorTimeout = 30 * time.Second
+
+// Collector coleta uma parte do inventário da máquina
+type Collector interface {
+	// Name identifica o coletor de forma única no registro
+	Name() string
+	// Collect executa a coleta respeitando o cancelamento do contexto
+	Collect(ctx context.Context) (any, error)
+}
+
+// machineInfoApplier é implementado pelos coletores embutidos que sabem
+// preencher os campos de model.MachineInfo com o valor coletado. Os
+// resultados dos demais coletores vão para MachineInfo.Extra.
+type machineInfoApplier interface {
+	apply(info *model.MachineInfo, value any)
+}
+
+// CollectorOptions controla a execução de um coletor registrado
+type CollectorOptions struct {
+	Enabled bool
+	Timeout time.Duration
+}
+
+// CollectorResult é o resultado da execução de um coletor
+type CollectorResult struct {
+	Name     string
+	Value    any
+	Err      error
+	Duration time.Duration
+}
+
+type registeredCollector struct {
+	collector Collector
+	options   CollectorOptions
+}
+
+// Registry mantém os coletores disponíveis e suas opções de execução
+type Registry struct {
+	mu         sync.RWMutex
+	collectors []*registeredCollector
+}
+
+// NewRegistry cria um registro vazio
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+var defaultRegistry = newDefaultRegistry()
+
+// DefaultRegistry retorna o registro com os coletores embutidos, usado
+// por CollectMachineInfo
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Register adiciona um coletor ao registro padrão
+func Register(c Collector, opts CollectorOptions) error {
+	return defaultRegistry.Register(c, opts)
+}
+
+// Register adiciona um coletor ao registro. Os coletores são executados
+// concorrentemente, mas os resultados seguem a ordem de registro.
+func (r *Registry) Register(c Collector, opts CollectorOptions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := c.Name()
+	if name == "" {
+		return fmt.Errorf("coletor sem nome")
+	}
+	if r.find(name) != nil {
+		return fmt.Errorf("coletor já registrado: %s", name)
+	}
+
+	r.collectors = append(r.collectors, &registeredCollector{collector: c, options: opts})
+	return nil
+}
+
+// Unregister remove um coletor do registro
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, rc := range r.collectors {
+		if rc.collector.Name() == name {
+			r.collectors = append(r.collectors[:i], r.collectors[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetEnabled ativa ou desativa um coletor
+func (r *Registry) SetEnabled(name string, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rc := r.find(name)
+	if rc == nil {
+		return fmt.Errorf("coletor não encontrado: %s", name)
+	}
+	rc.options.Enabled = enabled
+	return nil
+}
+
+// SetTimeout altera o tempo máximo de execução de um coletor
+func (r *Registry) SetTimeout(name string, timeout time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rc := r.find(name)
+	if rc == nil {
+		return fmt.Errorf("coletor não encontrado: %s", name)
+	}
+	rc.options.Timeout = timeout
+	return nil
+}
+
+// Options retorna as opções atuais de um coletor
+func (r *Registry) Options(name string) (CollectorOptions, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rc := r.find(name)
+	if rc == nil {
+		return CollectorOptions{}, false
+	}
+	return rc.options, true
+}
+
+// Names retorna os nomes dos coletores registrados, na ordem de registro
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.collectors))
+	for _, rc := range r.collectors {
+		names = append(names, rc.collector.Name())
+	}
+	return names
+}
+
+func (r *Registry) find(name string) *registeredCollector {
+	for _, rc := range r.collectors {
+		if rc.collector.Name() == name {
+			return rc
+		}
+	}
+	return nil
+}
+
+// Run executa concorrentemente todos os coletores ativos
+func (r *Registry) Run(ctx context.Context) []CollectorResult {
+	r.mu.RLock()
+	active := make([]registeredCollector, 0, len(r.collectors))
+	for _, rc := range r.collectors {
+		if rc.options.Enabled {
+			active = append(active, *rc)
+		}
+	}
+	r.mu.RUnlock()
+
+	results := make([]CollectorResult, len(active))
+	var wg sync.WaitGroup
+	for i, rc := range active {
+		wg.Add(1)
+		go func(i int, rc registeredCollector) {
+			defer wg.Done()
+			results[i] = runCollector(ctx, rc)
+		}(i, rc)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runCollector executa um coletor com timeout. Coletores que ignoram o
+// contexto continuam rodando em segundo plano, mas o resultado é descartado.
+func runCollector(ctx context.Context, rc registeredCollector) CollectorResult {
+	timeout := rc.options.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCollectorTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	name := rc.collector.Name()
+	start := time.Now()
+	done := make(chan CollectorResult, 1)
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- CollectorResult{Name: name, Err: fmt.Errorf("panic no coletor: %v", p)}
+			}
+		}()
+		value, err := rc.collector.Collect(ctx)
+		done <- CollectorResult{Name: name, Value: value, Err: err}
+	}()
+
+	var result CollectorResult
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result = CollectorResult{Name: name, Err: fmt.Errorf("tempo limite excedido após %v", timeout)}
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// CollectMachineInfo coleta o inventário usando o registro padrão
+func CollectMachineInfo() (*model.MachineInfo, error) {
+	return defaultRegistry.CollectMachineInfo(context.Background())
+}
+
+// CollectMachineInfo executa os coletores e monta o inventário. Falhas
+// individuais não interrompem a coleta e ficam em MachineInfo.CollectorErrors.
+func (r *Registry) CollectMachineInfo(ctx context.Context) (*model.MachineInfo, error) {
+	info := &model.MachineInfo{
+		OS:         runtime.GOOS,
+		USBDevices: make([]model.USBDevice, 0),
+	}
+
+	r.mu.RLock()
+	collectors := make(map[string]Collector, len(r.collectors))
+	for _, rc := range r.collectors {
+		collectors[rc.collector.Name()] = rc.collector
+	}
+	r.mu.RUnlock()
+
+	for _, result := range r.Run(ctx) {
+		if result.Err != nil {
+			if info.CollectorErrors == nil {
+				info.CollectorErrors = make(map[string]string)
+			}
+			info.CollectorErrors[result.Name] = result.Err.Error()
+			continue
+		}
+
+		if applier, ok := collectors[result.Name].(machineInfoApplier); ok {
+			applier.apply(info, result.Value)
+			continue
+		}
+
+		if info.Extra == nil {
+			info.Extra = make(map[string]any)
+		}
+		info.Extra[result.Name] = result.Value
+	}
+
+	return info, nil
+}
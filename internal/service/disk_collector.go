@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jaypipes/ghw"
+	"github.com/shirou/gopsutil/v3/disk"
+
+	"github.com/dev/falcon-agent/internal/config"
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// diskFilter é o filtro aplicado pelo coletor de discos, trocado pelo
+// agente quando a configuração é recarregada
+var diskFilter atomic.Pointer[config.DiskFilter]
+
+// setDiskFilter define quais discos entram nas próximas coletas
+func setDiskFilter(filter config.DiskFilter) {
+	diskFilter.Store(&filter)
+}
+
+// includeDisk indica se o disco passa pelo filtro. Discos sem mídia, como
+// leitores de cartão vazios, são sempre ignorados.
+func includeDisk(filter config.DiskFilter, d *ghw.Disk) bool {
+	if d.SizeBytes == 0 {
+		return false
+	}
+	if d.SizeBytes < uint64(filter.MinSizeGB)*1024*1024*1024 {
+		return false
+	}
+	if d.IsRemovable && !filter.Removable {
+		return false
+	}
+	for _, pattern := range filter.Exclude {
+		if ok, _ := path.Match(pattern, d.Name); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// collectDisks retorna os discos que passam pelo filtro configurado, com
+// as partições e o uso dos sistemas de arquivos montados
+func collectDisks(ctx context.Context) (any, error) {
+	diskInfo, err := ghw.Block()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := config.DefaultDiskFilter()
+	if f := diskFilter.Load(); f != nil {
+		filter = *f
+	}
+
+	// Sistemas de arquivos criados direto no disco, sem tabela de
+	// partições, não aparecem nas partições do ghw
+	mounts := make(map[string]disk.PartitionStat)
+	if stats, err := disk.PartitionsWithContext(ctx, false); err == nil {
+		for _, stat := range stats {
+			mounts[path.Base(stat.Device)] = stat
+		}
+	}
+
+	disks := make([]model.HDInfo, 0, len(diskInfo.Disks))
+	for _, d := range diskInfo.Disks {
+		if !includeDisk(filter, d) {
+			continue
+		}
+
+		hd := model.HDInfo{
+			Name:       d.Name,
+			Model:      known(d.Model),
+			Serial:     known(d.SerialNumber),
+			SizeGB:     d.SizeBytes / (1024 * 1024 * 1024),
+			SizeBytes:  d.SizeBytes,
+			Removable:  d.IsRemovable,
+			Rotational: d.DriveType == ghw.DRIVE_TYPE_HDD,
+			NVMe:       d.StorageController == ghw.STORAGE_CONTROLLER_NVME,
+			Partitions: make([]model.PartitionInfo, 0, len(d.Partitions)),
+		}
+		if d.StorageController != ghw.STORAGE_CONTROLLER_UNKNOWN {
+			hd.Controller = strings.ToLower(d.StorageController.String())
+		}
+
+		for _, p := range d.Partitions {
+			part := model.PartitionInfo{
+				Name:       p.Name,
+				Label:      known(p.FilesystemLabel),
+				Filesystem: known(p.Type),
+				MountPoint: p.MountPoint,
+				SizeBytes:  p.SizeBytes,
+				ReadOnly:   p.IsReadOnly,
+			}
+			if part.Label == "" {
+				part.Label = known(p.Label)
+			}
+			fillUsage(ctx, &part)
+			hd.Partitions = append(hd.Partitions, part)
+		}
+		if stat, ok := mounts[d.Name]; ok && len(d.Partitions) == 0 {
+			part := model.PartitionInfo{
+				Name:       d.Name,
+				Filesystem: stat.Fstype,
+				MountPoint: stat.Mountpoint,
+				SizeBytes:  d.SizeBytes,
+				ReadOnly:   slices.Contains(stat.Opts, "ro"),
+			}
+			fillUsage(ctx, &part)
+			hd.Partitions = append(hd.Partitions, part)
+		}
+		disks = append(disks, hd)
+	}
+	return disks, nil
+}
+
+// fillUsage preenche o espaço usado e livre de uma partição montada
+func fillUsage(ctx context.Context, part *model.PartitionInfo) {
+	if part.MountPoint == "" {
+		return
+	}
+	if usage, err := disk.UsageWithContext(ctx, part.MountPoint); err == nil {
+		part.UsedBytes = usage.Used
+		part.FreeBytes = usage.Free
+	}
+}
+
+// known troca o "unknown" usado pelo ghw para valores ausentes por vazio
+func known(value string) string {
+	if value == "unknown" {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/jaypipes/ghw"
+
+	"github.com/dev/falcon-agent/internal/config"
+)
+
+func TestIncludeDiskDefaultFilter(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+	tests := []struct {
+		disk ghw.Disk
+		want bool
+	}{
+		{ghw.Disk{Name: "sda", SizeBytes: 500 * gb}, true},
+		{ghw.Disk{Name: "nvme0n1", SizeBytes: 1000 * gb}, true},
+		{ghw.Disk{Name: "vda", SizeBytes: 10 * gb}, true},
+		{ghw.Disk{Name: "sdb", SizeBytes: 8 * gb}, false},
+		{ghw.Disk{Name: "sdc", SizeBytes: 64 * gb, IsRemovable: true}, false},
+		{ghw.Disk{Name: "mmcblk0"}, false},
+		{ghw.Disk{Name: "loop0", SizeBytes: 100 * gb}, false},
+		{ghw.Disk{Name: "zram0", SizeBytes: 16 * gb}, false},
+		{ghw.Disk{Name: "sr0", SizeBytes: 50 * gb}, false},
+		{ghw.Disk{Name: "dm-0", SizeBytes: 400 * gb}, false},
+		{ghw.Disk{Name: "md0", SizeBytes: 2000 * gb}, false},
+		{ghw.Disk{Name: "md127", SizeBytes: 2000 * gb}, false},
+		{ghw.Disk{Name: "nbd0", SizeBytes: 20 * gb}, false},
+		{ghw.Disk{Name: "zd0", SizeBytes: 20 * gb}, false},
+	}
+	filter := config.DefaultDiskFilter()
+	for _, tt := range tests {
+		if got := includeDisk(filter, &tt.disk); got != tt.want {
+			t.Errorf("includeDisk(%s) = %v, quer %v", tt.disk.Name, got, tt.want)
+		}
+	}
+}
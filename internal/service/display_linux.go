@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// sysfsDRMPath lista as placas de vídeo e suas saídas (card0-HDMI-A-1)
+const sysfsDRMPath = "/sys/class/drm"
+
+// readGPUDetails completa a placa com o driver, os IDs PCI quando o banco
+// de nomes não está disponível e a VRAM informada pelo driver amdgpu
+func readGPUDetails(gpu *model.GPUInfo) {
+	dir := filepath.Join(sysfsPCIPath, gpu.PCIAddress)
+
+	if gpu.Driver == "" {
+		if driver, err := os.Readlink(filepath.Join(dir, "driver")); err == nil {
+			gpu.Driver = filepath.Base(driver)
+		}
+	}
+	if gpu.Vendor == "" {
+		gpu.Vendor = strings.TrimPrefix(readSysfsAttr(dir, "vendor"), "0x")
+	}
+	if gpu.Model == "" {
+		gpu.Model = strings.TrimPrefix(readSysfsAttr(dir, "device"), "0x")
+	}
+
+	if vram, err := strconv.ParseUint(readSysfsAttr(dir, "mem_info_vram_total"), 10, 64); err == nil {
+		gpu.VRAMMB = vram / (1024 * 1024)
+	}
+}
+
+// collectMonitors retorna os monitores conectados às saídas de vídeo,
+// identificados pelo EDID
+func collectMonitors(ctx context.Context) (any, error) {
+	monitors := make([]model.MonitorInfo, 0)
+
+	entries, err := os.ReadDir(sysfsDRMPath)
+	if os.IsNotExist(err) {
+		// Sem placa de vídeo (servidores e máquinas virtuais)
+		return monitors, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		// As saídas têm o nome da placa seguido do conector: card0-HDMI-A-1
+		card, connector, ok := strings.Cut(entry.Name(), "-")
+		if !ok || !strings.HasPrefix(card, "card") {
+			continue
+		}
+		dir := filepath.Join(sysfsDRMPath, entry.Name())
+		if readSysfsAttr(dir, "status") != "connected" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, "edid"))
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		monitor, err := parseEDID(data)
+		if err != nil {
+			continue
+		}
+		monitor.Connector = connector
+		monitors = append(monitors, monitor)
+	}
+	return monitors, nil
+}
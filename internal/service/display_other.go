@@ -0,0 +1,19 @@
+//go:build !linux
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// readGPUDetails não tem fonte de detalhes fora do Linux
+func readGPUDetails(gpu *model.GPUInfo) {}
+
+// collectMonitors retorna erro nas plataformas sem EDID no sysfs
+func collectMonitors(ctx context.Context) (any, error) {
+	return nil, fmt.Errorf("leitura de monitores não suportada em %s", runtime.GOOS)
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// edidHeader inicia todo bloco base de EDID
+var edidHeader = []byte{0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00}
+
+// Tipos dos descritores de texto do EDID
+const (
+	edidDescriptorSerial = 0xff
+	edidDescriptorName   = 0xfc
+)
+
+// edidManufacturers traduz os IDs PNP dos fabricantes de monitores mais comuns
+var edidManufacturers = map[string]string{
+	"ACR": "Acer",
+	"AOC": "AOC",
+	"APP": "Apple",
+	"AUS": "ASUS",
+	"BNQ": "BenQ",
+	"BOE": "BOE",
+	"DEL": "Dell",
+	"GSM": "LG",
+	"HWP": "HP",
+	"LEN": "Lenovo",
+	"LGD": "LG Display",
+	"PHL": "Philips",
+	"SAM": "Samsung",
+	"SNY": "Sony",
+	"VSC": "ViewSonic",
+}
+
+// parseEDID lê o fabricante, o modelo, o número de série e a resolução
+// nativa do bloco base (128 bytes) do EDID de um monitor
+func parseEDID(data []byte) (model.MonitorInfo, error) {
+	var monitor model.MonitorInfo
+	if len(data) < 128 || !bytes.Equal(data[:8], edidHeader) {
+		return monitor, fmt.Errorf("EDID inválido")
+	}
+	var sum byte
+	for _, b := range data[:128] {
+		sum += b
+	}
+	if sum != 0 {
+		return monitor, fmt.Errorf("checksum do EDID inválido")
+	}
+
+	// Três letras de 5 bits (1 = "A") em big-endian
+	id := binary.BigEndian.Uint16(data[8:10])
+	pnp := string([]byte{
+		byte(id>>10&0x1f) + 'A' - 1,
+		byte(id>>5&0x1f) + 'A' - 1,
+		byte(id&0x1f) + 'A' - 1,
+	})
+	monitor.Manufacturer = pnp
+	if name, ok := edidManufacturers[pnp]; ok {
+		monitor.Manufacturer = name
+	}
+
+	productCode := binary.LittleEndian.Uint16(data[10:12])
+	monitor.Model = fmt.Sprintf("%s%04X", pnp, productCode)
+	if serial := binary.LittleEndian.Uint32(data[12:16]); serial != 0 {
+		monitor.Serial = fmt.Sprint(serial)
+	}
+
+	// Quatro descritores de 18 bytes: o primeiro com pixel clock é o modo
+	// preferido (resolução nativa); os demais podem trazer nome e serial
+	for offset := 54; offset < 126; offset += 18 {
+		d := data[offset : offset+18]
+		if d[0] != 0 || d[1] != 0 {
+			if monitor.Width == 0 {
+				monitor.Width = int(d[2]) | int(d[4]&0xf0)<<4
+				monitor.Height = int(d[5]) | int(d[7]&0xf0)<<4
+			}
+			continue
+		}
+		switch d[3] {
+		case edidDescriptorName:
+			if name := edidText(d[5:]); name != "" {
+				monitor.Model = name
+			}
+		case edidDescriptorSerial:
+			if serial := edidText(d[5:]); serial != "" {
+				monitor.Serial = serial
+			}
+		}
+	}
+
+	return monitor, nil
+}
+
+// edidText lê um texto de descritor, terminado por quebra de linha e
+// completado com espaços
+func edidText(b []byte) string {
+	if i := bytes.IndexByte(b, '\n'); i >= 0 {
+		b = b[:i]
+	}
+	return strings.TrimSpace(string(b))
+}
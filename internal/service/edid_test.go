@@ -0,0 +1,85 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// testEDID monta um bloco base de EDID de um monitor Dell 1920x1080, com
+// os descritores de texto informados, e acerta o checksum
+func testEDID(descriptors map[byte]string) []byte {
+	data := make([]byte, 128)
+	copy(data, edidHeader)
+	data[8], data[9] = 0x10, 0xac   // "DEL"
+	data[10], data[11] = 0xc7, 0xa0 // código do produto 0xA0C7
+	copy(data[12:16], []byte{0x78, 0x56, 0x34, 0x12})
+
+	// Modo preferido: 1920 (0x780) x 1080 (0x438)
+	copy(data[54:], []byte{0x3a, 0x02, 0x80, 0x18, 0x71, 0x38, 0x2d, 0x40})
+
+	offset := 72
+	for _, tag := range []byte{edidDescriptorName, edidDescriptorSerial} {
+		text, ok := descriptors[tag]
+		if !ok {
+			continue
+		}
+		d := data[offset : offset+18]
+		d[3] = tag
+		copy(d[5:], text+"\n            ")
+		offset += 18
+	}
+
+	var sum byte
+	for _, b := range data[:127] {
+		sum += b
+	}
+	data[127] = -sum
+	return data
+}
+
+func TestParseEDID(t *testing.T) {
+	tests := []struct {
+		name        string
+		descriptors map[byte]string
+		want        model.MonitorInfo
+	}{
+		{
+			name:        "com nome e serial",
+			descriptors: map[byte]string{edidDescriptorName: "DELL U2419H", edidDescriptorSerial: "ABC123"},
+			want:        model.MonitorInfo{Manufacturer: "Dell", Model: "DELL U2419H", Serial: "ABC123", Width: 1920, Height: 1080},
+		},
+		{
+			name: "só com os códigos numéricos",
+			want: model.MonitorInfo{Manufacturer: "Dell", Model: "DELA0C7", Serial: "305419896", Width: 1920, Height: 1080},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEDID(testEDID(tt.descriptors))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("parseEDID = %+v, quer %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEDIDInvalid(t *testing.T) {
+	valid := testEDID(nil)
+
+	corrupted := append([]byte(nil), valid...)
+	corrupted[20]++
+
+	for name, data := range map[string][]byte{
+		"curto":              valid[:100],
+		"sem cabeçalho":      append([]byte{0x01}, valid[1:]...),
+		"checksum incorreto": corrupted,
+	} {
+		if _, err := parseEDID(data); err == nil {
+			t.Errorf("%s: parseEDID não falhou", name)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jaypipes/ghw"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// collectGPUs retorna as placas de vídeo. Sem o banco de IDs PCI, o
+// fabricante e o modelo ficam com os IDs numéricos (ex.: "10de", "2204").
+func collectGPUs(ctx context.Context) (any, error) {
+	info, err := ghw.GPU()
+	if err != nil {
+		return nil, err
+	}
+
+	gpus := make([]model.GPUInfo, 0, len(info.GraphicsCards))
+	for _, card := range info.GraphicsCards {
+		gpu := model.GPUInfo{PCIAddress: card.Address}
+		if dev := card.DeviceInfo; dev != nil {
+			gpu.Driver = dev.Driver
+			if dev.Vendor != nil && dev.Vendor.Name != "unknown" {
+				gpu.Vendor = dev.Vendor.Name
+			}
+			if dev.Product != nil && dev.Product.Name != "unknown" {
+				gpu.Model = dev.Product.Name
+			}
+		}
+		readGPUDetails(&gpu)
+		gpus = append(gpus, gpu)
+	}
+	return gpus, nil
+}
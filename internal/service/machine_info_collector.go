@@ -1,10 +1,12 @@
 package service
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"os"
-	"runtime"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/google/gousb"
 	"github.com/jaypipes/ghw"
@@ -12,106 +14,179 @@ import (
 	"github.com/shirou/gopsutil/v3/host"
 
 	"github.com/dev/falcon-agent/internal/model"
+	"github.com/dev/falcon-agent/internal/usb"
+	"github.com/dev/falcon-agent/pkg/logger"
 )
 
-func CollectMachineInfo() (*model.MachineInfo, error) {
-	info := &model.MachineInfo{
-		USBDevices: make([]model.USBDevice, 0),
-	}
+// builtinCollector adapta uma função de coleta e a forma de aplicar seu
+// resultado em model.MachineInfo à interface Collector
+type builtinCollector struct {
+	name    string
+	collect func(ctx context.Context) (any, error)
+	fill    func(info *model.MachineInfo, value any)
+}
+
+func (c *builtinCollector) Name() string { return c.name }
 
-	// Sistema operacional
-	info.OS = runtime.GOOS
+func (c *builtinCollector) Collect(ctx context.Context) (any, error) { return c.collect(ctx) }
 
-	// Hostname
-	hostname, err := os.Hostname()
-	if err == nil {
-		info.Hostname = hostname
+func (c *builtinCollector) apply(info *model.MachineInfo, value any) { c.fill(info, value) }
+
+// newDefaultRegistry cria o registro com os coletores embutidos, todos ativos
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, c := range builtinCollectors() {
+		r.Register(c, CollectorOptions{Enabled: true, Timeout: DefaultCollectorTimeout})
 	}
+	return r
+}
+
+func builtinCollectors() []Collector {
+	return []Collector{
+		&builtinCollector{name: "hostname", collect: collectHostname, fill: func(info *model.MachineInfo, v any) {
+			info.Hostname = v.(string)
+		}},
+		&builtinCollector{name: "os", collect: collectOS, fill: func(info *model.MachineInfo, v any) {
+			info.OSDetails = v.(model.OSInfo)
+		}},
+		&builtinCollector{name: "memory", collect: collectMemory, fill: func(info *model.MachineInfo, v any) {
+			info.Memory = v.([]model.MemoryInfo)
+		}},
+		&builtinCollector{name: "disks", collect: collectDisks, fill: func(info *model.MachineInfo, v any) {
+			info.HDs = v.([]model.HDInfo)
+		}},
+		&builtinCollector{name: "disk_health", collect: collectDiskHealth, fill: func(info *model.MachineInfo, v any) {
+			info.DiskHealth = v.([]model.DiskHealth)
+		}},
+		&builtinCollector{name: "cpu", collect: collectProcessor, fill: func(info *model.MachineInfo, v any) {
+			info.Processor = v.(model.ProcessorInfo)
+		}},
+		&builtinCollector{name: "bios", collect: collectBIOS, fill: func(info *model.MachineInfo, v any) {
+			info.BIOS = v.(model.BIOSInfo)
+		}},
+		&builtinCollector{name: "baseboard", collect: collectBaseboard, fill: func(info *model.MachineInfo, v any) {
+			info.MotherboardSN = v.(string)
+		}},
+		&builtinCollector{name: "host", collect: collectHostID, fill: func(info *model.MachineInfo, v any) {
+			info.SerialNumber = v.(string)
+		}},
+		&builtinCollector{name: "usb", collect: collectUSBDevices, fill: func(info *model.MachineInfo, v any) {
+			info.USBDevices = v.([]model.USBDevice)
+		}},
+		&builtinCollector{name: "network", collect: collectNetwork, fill: func(info *model.MachineInfo, v any) {
+			info.Network = v.(model.NetworkInfo)
+		}},
+		&builtinCollector{name: "gpu", collect: collectGPUs, fill: func(info *model.MachineInfo, v any) {
+			info.GPUs = v.([]model.GPUInfo)
+		}},
+		&builtinCollector{name: "monitors", collect: collectMonitors, fill: func(info *model.MachineInfo, v any) {
+			info.Monitors = v.([]model.MonitorInfo)
+		}},
+		&builtinCollector{name: "pci", collect: collectPCIDevices, fill: func(info *model.MachineInfo, v any) {
+			info.PCIDevices = v.([]model.PCIDevice)
+		}},
+		&builtinCollector{name: "software", collect: collectSoftware, fill: func(info *model.MachineInfo, v any) {
+			info.Software = v.([]model.SoftwarePackage)
+		}},
+	}
+}
+
+// collectHostname retorna o nome da máquina
+func collectHostname(ctx context.Context) (any, error) {
+	return os.Hostname()
+}
 
-	// Memória
+// collectMemory retorna os módulos de memória instalados
+func collectMemory(ctx context.Context) (any, error) {
 	mems, err := ghw.Memory()
-	if err == nil {
-		if len(mems.Modules) > 0 {
-			for _, mod := range mems.Modules {
-				info.Memory = append(info.Memory, model.MemoryInfo{
-					Slot:         mod.Label,
-					SizeMB:       uint64(mod.SizeBytes / (1024 * 1024)),
-					Manufacturer: mod.Vendor,
-					SerialNumber: mod.SerialNumber,
-				})
-			}
-		} else {
-			// Fallback: mostra apenas o total de memória
-			info.Memory = append(info.Memory, model.MemoryInfo{
-				Slot:         "Total",
-				SizeMB:       uint64(mems.TotalPhysicalBytes / (1024 * 1024)),
-				Manufacturer: "N/A",
-				SerialNumber: "N/A",
-			})
-		}
+	if err != nil {
+		return nil, err
 	}
 
-	// HDs
-	diskInfo, err := ghw.Block()
-	if err == nil {
-		for _, d := range diskInfo.Disks {
-			if d.DriveType == ghw.DRIVE_TYPE_HDD || d.DriveType == ghw.DRIVE_TYPE_SSD {
-				sizeGB := uint64(d.SizeBytes / (1024 * 1024 * 1024))
-				modeloValido := d.Model != "" && d.Model != "unknown"
-				serialValido := d.SerialNumber != "" && d.SerialNumber != "unknown"
-				if sizeGB > 10 && (modeloValido || serialValido) {
-					info.HDs = append(info.HDs, model.HDInfo{
-						Model:  d.Model,
-						Serial: d.SerialNumber,
-						SizeGB: sizeGB,
-					})
-				}
-			}
+	var modules []model.MemoryInfo
+	if len(mems.Modules) > 0 {
+		for _, mod := range mems.Modules {
+			modules = append(modules, model.MemoryInfo{
+				Slot:         mod.Label,
+				SizeMB:       uint64(mod.SizeBytes / (1024 * 1024)),
+				Manufacturer: mod.Vendor,
+				SerialNumber: mod.SerialNumber,
+			})
 		}
+	} else {
+		// Fallback: mostra apenas o total de memória
+		modules = append(modules, model.MemoryInfo{
+			Slot:         "Total",
+			SizeMB:       uint64(mems.TotalPhysicalBytes / (1024 * 1024)),
+			Manufacturer: "N/A",
+			SerialNumber: "N/A",
+		})
 	}
+	return modules, nil
+}
 
-	// Processador
-	cpuInfo, err := cpu.Info()
-	if err == nil && len(cpuInfo) > 0 {
-		info.Processor = model.ProcessorInfo{
-			Model:        cpuInfo[0].ModelName,
-			Cores:        int(cpuInfo[0].Cores),
-			Threads:      len(cpuInfo),
-			FrequencyGHz: cpuInfo[0].Mhz / 1000.0,
-		}
+// collectProcessor retorna as informações do processador
+func collectProcessor(ctx context.Context) (any, error) {
+	cpuInfo, err := cpu.InfoWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(cpuInfo) == 0 {
+		return nil, fmt.Errorf("nenhum processador encontrado")
 	}
 
-	// BIOS
+	return model.ProcessorInfo{
+		Model:        cpuInfo[0].ModelName,
+		Cores:        int(cpuInfo[0].Cores),
+		Threads:      len(cpuInfo),
+		FrequencyGHz: cpuInfo[0].Mhz / 1000.0,
+	}, nil
+}
+
+// collectBIOS retorna as informações da BIOS
+func collectBIOS(ctx context.Context) (any, error) {
 	bios, err := ghw.BIOS()
-	if err == nil {
-		info.BIOS = model.BIOSInfo{
-			Vendor:      bios.Vendor,
-			Version:     bios.Version,
-			ReleaseDate: bios.Date,
-		}
+	if err != nil {
+		return nil, err
 	}
 
-	// Placa-mãe (Motherboard Serial)
+	return model.BIOSInfo{
+		Vendor:      bios.Vendor,
+		Version:     bios.Version,
+		ReleaseDate: bios.Date,
+	}, nil
+}
+
+// collectBaseboard retorna o número de série da placa-mãe
+func collectBaseboard(ctx context.Context) (any, error) {
 	baseboard, err := ghw.Baseboard()
-	if err == nil {
-		info.MotherboardSN = baseboard.SerialNumber
+	if err != nil {
+		return nil, err
 	}
+	return baseboard.SerialNumber, nil
+}
 
-	// Serial Number (geral)
-	hostInfo, err := host.Info()
-	if err == nil {
-		info.SerialNumber = hostInfo.HostID
+// collectHostID retorna o identificador da máquina usado como serial geral
+func collectHostID(ctx context.Context) (any, error) {
+	hostInfo, err := host.InfoWithContext(ctx)
+	if err != nil {
+		return nil, err
 	}
+	return hostInfo.HostID, nil
+}
+
+// collectUSBDevices lista os dispositivos USB conectados, exceto hubs
+func collectUSBDevices(ctx context.Context) (any, error) {
+	devices := make([]model.USBDevice, 0)
 
-	// Dispositivos USB
-	ctx := gousb.NewContext()
-	defer ctx.Close()
+	usbCtx := gousb.NewContext()
+	defer usbCtx.Close()
 
 	// Configura debug
-	ctx.Debug(3)
+	usbCtx.Debug(3)
 
 	// Lista todos os dispositivos
-	devs, err := ctx.OpenDevices(func(desc *gousb.DeviceDesc) bool {
+	devs, err := usbCtx.OpenDevices(func(desc *gousb.DeviceDesc) bool {
 		// Ignora hubs USB
 		if desc.Class == gousb.ClassHub {
 			return false
@@ -119,8 +194,9 @@ func CollectMachineInfo() (*model.MachineInfo, error) {
 		return true
 	})
 
+	// Erros de permissão em alguns dispositivos não impedem a listagem dos demais
 	if err != nil {
-		log.Printf("Erro ao listar dispositivos USB: %v", err)
+		logger.Default().With("component", "usb").Warn("Erro ao listar alguns dispositivos USB: %v", err)
 	}
 
 	for _, dev := range devs {
@@ -138,13 +214,27 @@ func CollectMachineInfo() (*model.MachineInfo, error) {
 			name = fmt.Sprintf("USB Device %04x:%04x", dev.Desc.Vendor, dev.Desc.Product)
 		}
 
-		info.USBDevices = append(info.USBDevices, model.USBDevice{
+		port := usbPortPath(dev.Desc)
+		devices = append(devices, model.USBDevice{
 			VendorID:  fmt.Sprintf("%04x", dev.Desc.Vendor),
 			ProductID: fmt.Sprintf("%04x", dev.Desc.Product),
 			Name:      name,
 			Serial:    serial,
+			Port:      port,
+
+			InterfaceClasses: usb.ReadInterfaceClasses(filepath.Join(usb.SysfsDevicesPath, port)),
 		})
 	}
 
-	return info, nil
+	return devices, nil
+}
+
+// usbPortPath monta o caminho da porta no mesmo formato usado pelo sysfs
+// e pelos eventos de hotplug (barramento seguido das portas: "1-2.3")
+func usbPortPath(desc *gousb.DeviceDesc) string {
+	ports := make([]string, len(desc.Path))
+	for i, p := range desc.Path {
+		ports[i] = strconv.Itoa(p)
+	}
+	return fmt.Sprintf("%d-%s", desc.Bus, strings.Join(ports, "."))
 }
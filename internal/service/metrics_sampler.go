@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/net"
+
+	"github.com/dev/falcon-agent/internal/metrics"
+)
+
+// ioCounters são os contadores acumulados de disco e rede usados para
+// calcular as taxas entre duas amostras
+type ioCounters struct {
+	time                  time.Time
+	diskRead, diskWritten uint64
+	netRecv, netSent      uint64
+}
+
+// virtualBlockPrefixes são os dispositivos de bloco que não são discos
+// físicos. As leituras de dm-*, md* e loop* repetem as dos discos por baixo
+// deles e somá-las contaria os mesmos bytes mais de uma vez.
+var virtualBlockPrefixes = []string{"loop", "ram", "zram", "dm-", "md"}
+
+// readIOCounters soma os contadores dos discos físicos e das interfaces de
+// rede, sem a interface de loopback
+func readIOCounters() (ioCounters, error) {
+	disks, err := disk.IOCounters()
+	if err != nil {
+		return ioCounters{time: time.Now()}, err
+	}
+	nets, err := net.IOCounters(true)
+	if err != nil {
+		return ioCounters{time: time.Now()}, err
+	}
+	return sumIOCounters(disks, nets, blockPartition), nil
+}
+
+// sumIOCounters soma os contadores dos discos inteiros, ignorando as
+// partições e os dispositivos virtuais, e das interfaces de rede exceto lo
+func sumIOCounters(disks map[string]disk.IOCountersStat, nets []net.IOCountersStat, isPartition func(name string) bool) ioCounters {
+	counters := ioCounters{time: time.Now()}
+	for name, d := range disks {
+		if virtualBlockDevice(name) || isPartition(name) {
+			continue
+		}
+		counters.diskRead += d.ReadBytes
+		counters.diskWritten += d.WriteBytes
+	}
+	for _, n := range nets {
+		if n.Name == "lo" {
+			continue
+		}
+		counters.netRecv += n.BytesRecv
+		counters.netSent += n.BytesSent
+	}
+	return counters
+}
+
+// virtualBlockDevice indica se o dispositivo de bloco não é um disco físico
+func virtualBlockDevice(name string) bool {
+	for _, prefix := range virtualBlockPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// rate calcula a taxa por segundo entre dois valores de um contador,
+// tratando como zero um contador que reiniciou
+func rate(previous, current uint64, elapsed time.Duration) float64 {
+	if current < previous || elapsed <= 0 {
+		return 0
+	}
+	return float64(current-previous) / elapsed.Seconds()
+}
+
+// primeSampler faz leituras descartadas de CPU, disco e rede que servem
+// de referência para a primeira amostra, de modo que todas as séries
+// comecem juntas e sem um valor sem sentido logo após a inicialização
+func (a *Agent) primeSampler() {
+	cpu.Percent(0, false)
+	cpu.Percent(0, true)
+	if counters, err := readIOCounters(); err == nil {
+		a.lastIO = counters
+	}
+	if a.Config().TopProcesses > 0 {
+		a.readProcesses(context.Background())
+	}
+}
+
+// sampleMetrics registra uma amostra de CPU, memória, swap e carga do
+// sistema e a lista de processos. Cada leitura que falha é apenas
+// registrada no log, sem impedir as demais.
+func (a *Agent) sampleMetrics() {
+	// Com intervalo zero o gopsutil calcula o uso desde a chamada anterior,
+	// ou seja, a média ao longo do intervalo de amostragem
+	now := time.Now()
+	var cpuUsage float64
+	if total, err := cpu.Percent(0, false); err != nil {
+		a.logger.Error("Erro ao ler uso de CPU: %v", err)
+	} else if len(total) > 0 {
+		cpuUsage = total[0]
+		a.record(now, "cpu_usage", cpuUsage)
+	}
+
+	if perCore, err := cpu.Percent(0, true); err != nil {
+		a.logger.Error("Erro ao ler uso de CPU por núcleo: %v", err)
+	} else {
+		for i, usage := range perCore {
+			if i < len(a.metrics.CPUCoreUsage) {
+				a.record(now, metrics.CoreMetricName(i), usage)
+			}
+		}
+	}
+
+	if vm, err := mem.VirtualMemory(); err != nil {
+		a.logger.Error("Erro ao ler uso de memória: %v", err)
+	} else {
+		a.record(now, "memory_usage", vm.UsedPercent)
+	}
+
+	if swap, err := mem.SwapMemory(); err != nil {
+		a.logger.Error("Erro ao ler uso de swap: %v", err)
+	} else {
+		a.record(now, "swap_usage", swap.UsedPercent)
+	}
+
+	if avg, err := load.Avg(); err != nil {
+		a.logger.Error("Erro ao ler carga do sistema: %v", err)
+	} else {
+		a.record(now, "load1", avg.Load1)
+		a.record(now, "load5", avg.Load5)
+		a.record(now, "load15", avg.Load15)
+	}
+
+	a.sampleProcesses(cpuUsage)
+
+	counters, err := readIOCounters()
+	if err != nil {
+		a.logger.Error("Erro ao ler contadores de disco e rede: %v", err)
+		return
+	}
+	if previous := a.lastIO; !previous.time.IsZero() {
+		elapsed := counters.time.Sub(previous.time)
+		a.record(now, "disk_read_bytes", rate(previous.diskRead, counters.diskRead, elapsed))
+		a.record(now, "disk_write_bytes", rate(previous.diskWritten, counters.diskWritten, elapsed))
+		a.record(now, "net_recv_bytes", rate(previous.netRecv, counters.netRecv, elapsed))
+		a.record(now, "net_sent_bytes", rate(previous.netSent, counters.netSent, elapsed))
+	}
+	a.lastIO = counters
+}
+
+func (a *Agent) record(ts time.Time, name string, value float64) {
+	if err := a.metrics.Record(name, ts, value); err != nil {
+		a.logger.Error("Erro ao registrar métrica %s: %v", name, err)
+	}
+}
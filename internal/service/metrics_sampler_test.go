@@ -0,0 +1,30 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+func TestSumIOCounters(t *testing.T) {
+	disks := make(map[string]disk.IOCountersStat)
+	for _, name := range []string{"sda", "sda1", "sda2", "nvme0n1", "nvme0n1p1", "dm-0", "md0", "loop3", "ram0", "zram0"} {
+		disks[name] = disk.IOCountersStat{Name: name, ReadBytes: 100, WriteBytes: 10}
+	}
+	partitions := map[string]bool{"sda1": true, "sda2": true, "nvme0n1p1": true}
+	nets := []net.IOCountersStat{
+		{Name: "lo", BytesRecv: 1000, BytesSent: 1000},
+		{Name: "eth0", BytesRecv: 50, BytesSent: 5},
+		{Name: "wlan0", BytesRecv: 20, BytesSent: 2},
+	}
+
+	got := sumIOCounters(disks, nets, func(name string) bool { return partitions[name] })
+	// Só sda e nvme0n1 são discos inteiros
+	if got.diskRead != 200 || got.diskWritten != 20 {
+		t.Errorf("disco = %d lidos, %d escritos; quer 200, 20", got.diskRead, got.diskWritten)
+	}
+	if got.netRecv != 70 || got.netSent != 7 {
+		t.Errorf("rede = %d recebidos, %d enviados; quer 70, 7", got.netRecv, got.netSent)
+	}
+}
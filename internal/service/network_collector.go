@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"net"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// collectNetwork retorna as interfaces de rede, exceto a de loopback, com
+// seus endereços e gateways, e os servidores DNS do sistema
+func collectNetwork(ctx context.Context) (any, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	gateways := defaultGateways()
+	info := model.NetworkInfo{
+		Interfaces: make([]model.NetworkInterface, 0, len(ifaces)),
+		DNSServers: dnsServers(),
+	}
+
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+
+		ni := model.NetworkInterface{
+			Name:     iface.Name,
+			MAC:      iface.HardwareAddr.String(),
+			IPv4:     make([]string, 0),
+			IPv6:     make([]string, 0),
+			Gateways: gateways[iface.Name],
+			MTU:      iface.MTU,
+			Up:       iface.Flags&net.FlagUp != 0 && iface.Flags&net.FlagRunning != 0,
+			Type:     model.InterfaceWired,
+		}
+
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			if ipNet.IP.To4() != nil {
+				ni.IPv4 = append(ni.IPv4, ipNet.String())
+			} else {
+				ni.IPv6 = append(ni.IPv6, ipNet.String())
+			}
+		}
+
+		readInterfaceDetails(&ni)
+		info.Interfaces = append(info.Interfaces, ni)
+	}
+
+	return info, nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+const (
+	// sysfsNetPath é o diretório do sysfs com as interfaces de rede
+	sysfsNetPath = "/sys/class/net"
+	// resolvConf é a configuração de DNS do sistema
+	resolvConf = "/etc/resolv.conf"
+	// resolvedConf lista os servidores reais quando o systemd-resolved
+	// ocupa o resolv.conf com o seu endereço local (127.0.0.53)
+	resolvedConf = "/run/systemd/resolve/resolv.conf"
+)
+
+// readInterfaceDetails completa a interface com o estado do link, a
+// velocidade, o driver e o tipo lidos do sysfs
+func readInterfaceDetails(iface *model.NetworkInterface) {
+	dir := filepath.Join(sysfsNetPath, iface.Name)
+
+	// operstate reflete o link físico; "unknown" é comum em interfaces
+	// virtuais, que mantêm o estado das flags
+	if state := readSysfsAttr(dir, "operstate"); state != "" && state != "unknown" {
+		iface.Up = state == "up"
+	}
+
+	// A velocidade só é informada com o link ativo (-1 ou erro sem link)
+	if speed, err := strconv.Atoi(readSysfsAttr(dir, "speed")); err == nil && speed > 0 {
+		iface.SpeedMbps = speed
+	}
+
+	if driver, err := os.Readlink(filepath.Join(dir, "device", "driver")); err == nil {
+		iface.Driver = filepath.Base(driver)
+	}
+
+	switch {
+	case sysfsExists(dir, "wireless") || sysfsExists(dir, "phy80211"):
+		iface.Type = model.InterfaceWireless
+	case !sysfsExists(dir, "device"):
+		iface.Type = model.InterfaceVirtual
+	default:
+		iface.Type = model.InterfaceWired
+	}
+}
+
+// defaultGateways retorna os gateways das rotas padrão IPv4 e IPv6, pelo
+// nome da interface de saída
+func defaultGateways() map[string][]string {
+	gateways := make(map[string][]string)
+
+	// /proc/net/route: Iface Destination Gateway Flags ..., em hexadecimal
+	// na ordem de bytes da máquina
+	forEachProcLine("/proc/net/route", func(fields []string) {
+		if len(fields) < 3 || fields[1] != "00000000" {
+			return
+		}
+		raw, err := strconv.ParseUint(fields[2], 16, 32)
+		if err != nil || raw == 0 {
+			return
+		}
+		ip := make(net.IP, 4)
+		binary.NativeEndian.PutUint32(ip, uint32(raw))
+		gateways[fields[0]] = appendUnique(gateways[fields[0]], ip.String())
+	})
+
+	// /proc/net/ipv6_route: destino, prefixo, origem, prefixo, próximo
+	// salto, métrica, ..., interface
+	forEachProcLine("/proc/net/ipv6_route", func(fields []string) {
+		if len(fields) < 10 || fields[1] != "00" || strings.Trim(fields[0], "0") != "" {
+			return
+		}
+		raw, err := hex.DecodeString(fields[4])
+		if err != nil || len(raw) != net.IPv6len {
+			return
+		}
+		ip := net.IP(raw)
+		if ip.IsUnspecified() {
+			return
+		}
+		gateways[fields[9]] = appendUnique(gateways[fields[9]], ip.String())
+	})
+
+	return gateways
+}
+
+// dnsServers retorna os servidores DNS do resolv.conf
+func dnsServers() []string {
+	servers := readNameservers(resolvConf)
+	if len(servers) == 1 && servers[0] == "127.0.0.53" {
+		if upstream := readNameservers(resolvedConf); len(upstream) > 0 {
+			return upstream
+		}
+	}
+	return servers
+}
+
+func readNameservers(path string) []string {
+	servers := make([]string, 0)
+	forEachProcLine(path, func(fields []string) {
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			servers = appendUnique(servers, fields[1])
+		}
+	})
+	return servers
+}
+
+// forEachProcLine chama fn com os campos de cada linha do arquivo
+func forEachProcLine(path string, fn func(fields []string)) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fn(strings.Fields(scanner.Text()))
+	}
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package service
+
+import "github.com/dev/falcon-agent/internal/model"
+
+// readInterfaceDetails não tem fonte de detalhes fora do Linux; a interface
+// fica com os dados de net.Interfaces
+func readInterfaceDetails(iface *model.NetworkInterface) {}
+
+// defaultGateways não é suportado fora do Linux
+func defaultGateways() map[string][]string {
+	return nil
+}
+
+// dnsServers não é suportado fora do Linux
+func dnsServers() []string {
+	return make([]string, 0)
+}
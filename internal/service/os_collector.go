@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/host"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// containerSystems são os valores de virtualização do gopsutil que
+// correspondem a contêineres
+var containerSystems = map[string]bool{
+	"docker": true, "lxc": true, "rkt": true, "openvz": true, "linux-vserver": true,
+}
+
+// collectOS retorna a distribuição, o kernel, o tempo ligado e o ambiente
+// em que o sistema roda (máquina virtual, contêiner, Secure Boot)
+func collectOS(ctx context.Context) (any, error) {
+	hostInfo, err := host.InfoWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	info := model.OSInfo{
+		Name:          hostInfo.Platform,
+		Version:       hostInfo.PlatformVersion,
+		ID:            hostInfo.Platform,
+		VersionID:     hostInfo.PlatformVersion,
+		Kernel:        hostInfo.KernelVersion,
+		Arch:          hostInfo.KernelArch,
+		BootTime:      time.Unix(int64(hostInfo.BootTime), 0).UTC(),
+		UptimeSeconds: hostInfo.Uptime,
+		Timezone:      systemTimezone(),
+		Locale:        systemLocale(),
+		Container:     containerType(),
+		SecureBoot:    secureBootState(),
+	}
+	// O gopsutil também informa a função "host" quando a máquina é um
+	// hipervisor, e mistura contêineres com hipervisores
+	if hostInfo.VirtualizationRole == "guest" {
+		if containerSystems[hostInfo.VirtualizationSystem] {
+			if info.Container == "" {
+				info.Container = hostInfo.VirtualizationSystem
+			}
+		} else {
+			info.Virtualization = hostInfo.VirtualizationSystem
+		}
+	}
+	readOSRelease(&info)
+	return info, nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// secureBootVar é a variável EFI com o estado do Secure Boot. O conteúdo
+// tem 4 bytes de atributos seguidos de 1 byte com o valor.
+const secureBootVar = "/sys/firmware/efi/efivars/SecureBoot-8be4df61-93ca-11d2-aa0d-e7e5c8e8c9d3"
+
+// readOSRelease completa o sistema com o nome e a versão da distribuição
+// lidos do os-release, mais completos que os do gopsutil
+func readOSRelease(info *model.OSInfo) {
+	values := readKeyValueFile("/etc/os-release")
+	if len(values) == 0 {
+		values = readKeyValueFile("/usr/lib/os-release")
+	}
+
+	if v := values["NAME"]; v != "" {
+		info.Name = v
+	}
+	if v := values["VERSION"]; v != "" {
+		info.Version = v
+	} else if v := values["VERSION_ID"]; v != "" {
+		// Distribuições contínuas, como o Arch, não têm versão
+		info.Version = v
+	}
+	if v := values["ID"]; v != "" {
+		info.ID = v
+	}
+	if v, ok := values["VERSION_ID"]; ok {
+		info.VersionID = v
+	}
+}
+
+// systemTimezone retorna o fuso horário configurado no sistema, pelo link
+// /etc/localtime, ou a abreviação do fuso local
+func systemTimezone() string {
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if _, zone, ok := strings.Cut(target, "zoneinfo/"); ok {
+			return zone
+		}
+	}
+	if data, err := os.ReadFile("/etc/timezone"); err == nil {
+		if zone := strings.TrimSpace(string(data)); zone != "" {
+			return zone
+		}
+	}
+	zone, _ := time.Now().Zone()
+	return zone
+}
+
+// systemLocale retorna o idioma padrão do sistema. O ambiente do agente
+// só é usado como último recurso, pois sob o systemd ele costuma ser vazio.
+func systemLocale() string {
+	for _, file := range []string{"/etc/locale.conf", "/etc/default/locale"} {
+		if lang := readKeyValueFile(file)["LANG"]; lang != "" {
+			return lang
+		}
+	}
+	for _, name := range []string{"LC_ALL", "LANG"} {
+		if lang := os.Getenv(name); lang != "" {
+			return lang
+		}
+	}
+	return ""
+}
+
+// containerType identifica o ambiente de contêiner pelos arquivos que os
+// motores criam e pela variável container do processo 1
+func containerType() string {
+	if _, err := os.Stat("/.dockerenv"); err == nil {
+		return "docker"
+	}
+	if _, err := os.Stat("/run/.containerenv"); err == nil {
+		return "podman"
+	}
+	environ, err := os.ReadFile("/proc/1/environ")
+	if err != nil {
+		return ""
+	}
+	for _, entry := range bytes.Split(environ, []byte{0}) {
+		if value, ok := bytes.CutPrefix(entry, []byte("container=")); ok {
+			return string(value)
+		}
+	}
+	return ""
+}
+
+// secureBootState lê o estado do Secure Boot das variáveis EFI
+func secureBootState() string {
+	if !sysfsExists("/sys/firmware/efi") {
+		return model.SecureBootUnsupported
+	}
+	data, err := os.ReadFile(secureBootVar)
+	if err != nil || len(data) < 5 {
+		return ""
+	}
+	if data[4] == 1 {
+		return model.SecureBootEnabled
+	}
+	return model.SecureBootDisabled
+}
+
+// readKeyValueFile lê um arquivo no formato CHAVE=valor do os-release,
+// removendo as aspas dos valores
+func readKeyValueFile(path string) map[string]string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	values := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || strings.HasPrefix(key, "#") {
+			continue
+		}
+		values[key] = strings.Trim(value, `"'`)
+	}
+	return values
+}
@@ -0,0 +1,38 @@
+//go:build !linux
+
+package service
+
+import (
+	"os"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// readOSRelease não tem os-release fora do Linux; o sistema fica com o
+// nome e a versão informados pelo gopsutil
+func readOSRelease(info *model.OSInfo) {}
+
+// systemTimezone retorna a abreviação do fuso horário local
+func systemTimezone() string {
+	zone, _ := time.Now().Zone()
+	return zone
+}
+
+// systemLocale retorna o idioma do ambiente do agente
+func systemLocale() string {
+	if lang := os.Getenv("LC_ALL"); lang != "" {
+		return lang
+	}
+	return os.Getenv("LANG")
+}
+
+// containerType não é suportado fora do Linux
+func containerType() string {
+	return ""
+}
+
+// secureBootState não é suportado fora do Linux
+func secureBootState() string {
+	return ""
+}
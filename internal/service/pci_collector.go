@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jaypipes/ghw"
+	"github.com/jaypipes/ghw/pkg/pci"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// collectPCIDevices retorna os dispositivos PCI com os nomes do banco de
+// IDs PCI (pci.ids). Sem o banco, os dispositivos são listados pelo sysfs
+// com os IDs numéricos e o nome genérico da classe.
+func collectPCIDevices(ctx context.Context) (any, error) {
+	info, err := ghw.PCI()
+	if err != nil {
+		devices, sysfsErr := readPCIDevices()
+		if sysfsErr != nil {
+			return nil, fmt.Errorf("%v; %v", err, sysfsErr)
+		}
+		return devices, nil
+	}
+
+	devices := make([]model.PCIDevice, 0, len(info.Devices))
+	for _, dev := range info.Devices {
+		devices = append(devices, pciDeviceFromGHW(dev))
+	}
+	return devices, nil
+}
+
+func pciDeviceFromGHW(dev *pci.Device) model.PCIDevice {
+	device := model.PCIDevice{Address: dev.Address, Driver: dev.Driver}
+	if dev.Class != nil {
+		device.ClassID = dev.Class.ID
+		device.Class = known(dev.Class.Name)
+		if device.Class == "" {
+			device.Class = pciClassNames[dev.Class.ID]
+		}
+	}
+	if dev.Subclass != nil {
+		device.ClassID += dev.Subclass.ID
+		// A subclasse é mais específica ("Ethernet controller" em vez de
+		// "Network controller")
+		if name := known(dev.Subclass.Name); name != "" {
+			device.Class = name
+		}
+	}
+	// Sem o banco de IDs o ghw preenche os nomes com "unknown"; os IDs
+	// numéricos continuam identificando o dispositivo
+	if dev.Vendor != nil {
+		device.VendorID, device.Vendor = dev.Vendor.ID, known(dev.Vendor.Name)
+	}
+	if dev.Product != nil {
+		device.ProductID, device.Product = dev.Product.ID, known(dev.Product.Name)
+	}
+	if sub := dev.Subsystem; sub != nil && sub.VendorID != "" {
+		device.Subsystem = sub.VendorID + ":" + sub.ID
+		if name := known(sub.Name); name != "" {
+			device.Subsystem += " " + name
+		}
+	}
+	return device
+}
+
+// pciClassNames são os nomes das classes base PCI, usados quando o banco
+// de IDs não está instalado
+var pciClassNames = map[string]string{
+	"00": "Unclassified device",
+	"01": "Mass storage controller",
+	"02": "Network controller",
+	"03": "Display controller",
+	"04": "Multimedia controller",
+	"05": "Memory controller",
+	"06": "Bridge",
+	"07": "Communication controller",
+	"08": "Generic system peripheral",
+	"09": "Input device controller",
+	"0a": "Docking station",
+	"0b": "Processor",
+	"0c": "Serial bus controller",
+	"0d": "Wireless controller",
+	"0e": "Intelligent controller",
+	"0f": "Satellite communications controller",
+	"10": "Encryption controller",
+	"11": "Signal processing controller",
+	"12": "Processing accelerators",
+	"13": "Non-Essential Instrumentation",
+}
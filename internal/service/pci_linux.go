@@ -0,0 +1,48 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// readPCIDevices lista os dispositivos PCI direto do sysfs, apenas com os
+// IDs numéricos
+func readPCIDevices() ([]model.PCIDevice, error) {
+	entries, err := os.ReadDir(sysfsPCIPath)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]model.PCIDevice, 0, len(entries))
+	for _, entry := range entries {
+		dir := filepath.Join(sysfsPCIPath, entry.Name())
+		attr := func(name string) string {
+			return strings.TrimPrefix(readSysfsAttr(dir, name), "0x")
+		}
+
+		// class tem classe, subclasse e interface: 0x020000
+		class := attr("class")
+		if len(class) >= 4 {
+			class = class[:4]
+		}
+
+		device := model.PCIDevice{
+			Address:   entry.Name(),
+			ClassID:   class,
+			Class:     pciClassNames[class[:min(2, len(class))]],
+			VendorID:  attr("vendor"),
+			ProductID: attr("device"),
+		}
+		if subVendor := attr("subsystem_vendor"); subVendor != "" {
+			device.Subsystem = subVendor + ":" + attr("subsystem_device")
+		}
+		if driver, err := os.Readlink(filepath.Join(dir, "driver")); err == nil {
+			device.Driver = filepath.Base(driver)
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
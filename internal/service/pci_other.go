@@ -0,0 +1,15 @@
+//go:build !linux
+
+package service
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// readPCIDevices não é suportado fora do Linux
+func readPCIDevices() ([]model.PCIDevice, error) {
+	return nil, fmt.Errorf("listagem de PCI pelo sysfs não suportada em %s", runtime.GOOS)
+}
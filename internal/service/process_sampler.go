@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/dev/falcon-agent/internal/metrics"
+)
+
+// processEntry guarda os dados de um processo que não mudam entre as
+// amostras e o tempo de CPU acumulado na amostra anterior
+type processEntry struct {
+	created  int64
+	user     string
+	name     string
+	command  string
+	cpuTotal float64
+}
+
+// processCounters são os processos vistos na última leitura, pelo PID
+type processCounters struct {
+	time      time.Time
+	processes map[int32]*processEntry
+}
+
+// readProcesses lê os processos em execução e calcula o uso de CPU de cada
+// um desde a leitura anterior. Usuário, nome e linha de comando só são
+// lidos na primeira vez que o processo aparece. Processos que terminam
+// durante a leitura são ignorados.
+func (a *Agent) readProcesses(ctx context.Context) ([]metrics.ProcessSample, error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	previous := a.lastProcs
+	elapsed := now.Sub(previous.time).Seconds() * float64(runtime.NumCPU())
+	current := processCounters{time: now, processes: make(map[int32]*processEntry, len(procs))}
+
+	samples := make([]metrics.ProcessSample, 0, len(procs))
+	for _, p := range procs {
+		created, err := p.CreateTimeWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		times, err := p.TimesWithContext(ctx)
+		if err != nil {
+			continue
+		}
+
+		// Um PID reaproveitado por outro processo conta como processo novo
+		entry, known := previous.processes[p.Pid]
+		if !known || entry.created != created {
+			entry = &processEntry{created: created}
+			entry.user, _ = p.UsernameWithContext(ctx)
+			entry.name, _ = p.NameWithContext(ctx)
+			entry.command, _ = p.CmdlineWithContext(ctx)
+			known = false
+		}
+
+		cpuTotal := times.User + times.System
+		sample := metrics.ProcessSample{
+			PID:       p.Pid,
+			User:      entry.user,
+			Name:      entry.name,
+			Command:   entry.command,
+			StartTime: time.UnixMilli(created),
+		}
+		// Um processo criado depois da leitura anterior tem todo o seu
+		// tempo de CPU no intervalo; os demais sem referência ficam com zero
+		switch {
+		case previous.time.IsZero() || elapsed <= 0:
+		case known && cpuTotal >= entry.cpuTotal:
+			sample.CPUPercent = (cpuTotal - entry.cpuTotal) / elapsed * 100
+		case !known && sample.StartTime.After(previous.time):
+			sample.CPUPercent = cpuTotal / elapsed * 100
+		}
+		if mem, err := p.MemoryInfoWithContext(ctx); err == nil {
+			sample.RSSBytes = mem.RSS
+		}
+		// Sem permissão para ler os descritores de outros usuários o
+		// número de arquivos abertos fica zerado
+		if fds, err := p.NumFDsWithContext(ctx); err == nil {
+			sample.OpenFiles = fds
+		}
+
+		entry.cpuTotal = cpuTotal
+		current.processes[p.Pid] = entry
+		samples = append(samples, sample)
+	}
+
+	a.lastProcs = current
+	return samples, nil
+}
+
+// sampleProcesses atualiza a lista de processos e guarda no histórico os
+// que mais usaram CPU, junto com o uso total da máquina na mesma amostra
+func (a *Agent) sampleProcesses(cpuUsage float64) {
+	top := a.Config().TopProcesses
+	if top == 0 {
+		a.mu.Lock()
+		a.processes = nil
+		a.mu.Unlock()
+		return
+	}
+
+	samples, err := a.readProcesses(context.Background())
+	if err != nil {
+		a.logger.Error("Erro ao ler os processos: %v", err)
+		return
+	}
+
+	a.mu.Lock()
+	a.processes = samples
+	a.mu.Unlock()
+
+	// A lista entregue aos leitores não é reordenada
+	ranked := make([]metrics.ProcessSample, len(samples))
+	copy(ranked, samples)
+	metrics.SortProcesses(ranked, metrics.SortByCPU)
+	if len(ranked) > top {
+		ranked = ranked[:top]
+	}
+	a.metrics.TopProcesses.Add(metrics.TopProcesses{
+		Timestamp: a.lastProcs.time,
+		CPUUsage:  cpuUsage,
+		Processes: ranked,
+	})
+}
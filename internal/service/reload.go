@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/api"
+	"github.com/dev/falcon-agent/internal/config"
+	"github.com/dev/falcon-agent/pkg/logger"
+)
+
+// levelSetter é implementado pelos loggers que aceitam troca de nível
+type levelSetter interface {
+	SetLevel(level string) error
+}
+
+// rotationSetter é implementado pelos loggers que gravam em arquivo rotacionado
+type rotationSetter interface {
+	SetRotation(opts logger.RotateOptions)
+}
+
+// watchConfig recarrega a configuração quando o arquivo de configuração
+// ou a política USB mudam, ou quando o processo recebe SIGHUP
+func (a *Agent) watchConfig(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	changed := make(chan struct{}, 1)
+	watch := func(files []string) context.CancelFunc {
+		watchCtx, cancel := context.WithCancel(ctx)
+		go func() {
+			err := config.Watch(watchCtx, files, func() {
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			})
+			if err != nil {
+				a.logger.Error("Erro ao observar os arquivos de configuração: %v", err)
+			}
+		}()
+		return cancel
+	}
+
+	files := a.Config().WatchFiles()
+	cancel := watch(files)
+	defer func() { cancel() }()
+
+	for {
+		select {
+		case <-hup:
+			a.logger.Info("SIGHUP recebido, recarregando a configuração")
+		case <-changed:
+			a.logger.Info("Arquivo de configuração alterado, recarregando")
+		case <-ctx.Done():
+			return
+		}
+
+		a.reloadConfig()
+
+		// A política USB pode ter mudado de lugar
+		if newFiles := a.Config().WatchFiles(); !slices.Equal(newFiles, files) {
+			cancel()
+			files = newFiles
+			cancel = watch(files)
+		}
+	}
+}
+
+// reloadConfig lê novamente a configuração e a aplica, mantendo a anterior
+// se a nova for inválida
+func (a *Agent) reloadConfig() {
+	cfg, err := a.Config().Reload()
+	if err != nil {
+		a.logger.Error("Configuração inválida, mantendo a anterior:\n%v", err)
+		return
+	}
+	if err := a.Reload(cfg); err != nil {
+		a.logger.Error("Erro ao aplicar a nova configuração, mantendo a anterior: %v", err)
+	}
+}
+
+// Reload aplica uma nova configuração ao agente em execução, sem perder o
+// histórico em memória. Tudo o que pode falhar (coletores desconhecidos,
+// política USB inválida, endereço da API ocupado) é verificado antes da
+// troca; em caso de erro a configuração anterior continua em vigor.
+func (a *Agent) Reload(cfg *config.Config) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	old := a.Config()
+
+	// Diretórios, formato e destinos do log e modo de execução só mudam com
+	// o agente reiniciado
+	if cfg.DataPath != old.DataPath || cfg.LogPath != old.LogPath || cfg.LogFormat != old.LogFormat || cfg.Headless != old.Headless ||
+		!slices.Equal(cfg.LogSinks, old.LogSinks) || cfg.LogSyslog != old.LogSyslog {
+		a.logger.Warn("data_path, log.path, log.format, log.sinks, log.syslog e headless só mudam após reiniciar o agente; valores anteriores mantidos")
+		cfg.DataPath, cfg.LogPath, cfg.LogFormat, cfg.Headless = old.DataPath, old.LogPath, old.LogFormat, old.Headless
+		cfg.LogSinks, cfg.LogSyslog = old.LogSinks, old.LogSyslog
+	}
+
+	if err := CheckCollectors(cfg); err != nil {
+		return err
+	}
+
+	policy, err := loadUSBPolicy(cfg.USBPolicyPath)
+	if err != nil {
+		return &config.KeyError{Key: "usb.policy_path", Err: err}
+	}
+
+	var newAPI *api.Server
+	if cfg.APIAddr != old.APIAddr && cfg.APIAddr != "" {
+		newAPI = api.New(cfg.APIAddr, a, a.logger.With("component", "api"))
+		if err := newAPI.Start(); err != nil {
+			return &config.KeyError{Key: "api.addr", Err: err}
+		}
+	}
+
+	// A partir daqui nada falha: a nova configuração entra em vigor
+	a.config.Store(cfg)
+
+	changes := config.Diff(old, cfg)
+	for _, change := range changes {
+		a.logger.Info("Configuração alterada: %s", change)
+	}
+
+	if setter, ok := a.logger.(levelSetter); ok && cfg.LogLevel != old.LogLevel {
+		setter.SetLevel(cfg.LogLevel)
+	}
+	if setter, ok := a.logger.(rotationSetter); ok && cfg.LogRotation != old.LogRotation {
+		setter.SetRotation(cfg.LogRotation)
+	}
+
+	a.applyCollectors(cfg)
+
+	if a.store != nil && cfg.MetricsRetention != old.MetricsRetention {
+		a.store.SetOptions(cfg.MetricsRetention)
+		if err := a.store.ApplyRetention(time.Now()); err != nil {
+			a.logger.Error("Erro ao aplicar retenção de métricas: %v", err)
+		}
+	}
+
+	if cfg.APIAddr != old.APIAddr {
+		if a.api != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := a.api.Stop(ctx); err != nil {
+				a.logger.Error("Erro ao parar a API HTTP em %s: %v", old.APIAddr, err)
+			}
+			cancel()
+		}
+		a.api = newAPI
+	}
+
+	if current := a.usbEnforcer(); policy == nil && current != nil {
+		a.setUSBPolicy(nil)
+	} else if policy != nil && (current == nil || !reflect.DeepEqual(policy, current.Policy())) {
+		a.setUSBPolicy(policy)
+	}
+
+	if cfg.ServerURL != old.ServerURL || cfg.EnrollmentToken != old.EnrollmentToken ||
+		cfg.ReportInterval != old.ReportInterval || cfg.SpoolMaxBytes != old.SpoolMaxBytes {
+		a.stopFleetReporter()
+		if cfg.ServerURL != "" {
+			if err := a.startFleetReporter(); err != nil {
+				a.logger.Error("Erro ao iniciar o envio ao servidor: %v", err)
+			}
+		}
+	}
+
+	select {
+	case a.reconfigure <- struct{}{}:
+	default:
+	}
+
+	if len(changes) == 0 {
+		a.logger.Info("Configuração recarregada sem mudanças")
+	}
+	return nil
+}
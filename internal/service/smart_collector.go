@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/diskhealth"
+	"github.com/dev/falcon-agent/internal/model"
+	"github.com/dev/falcon-agent/pkg/logger"
+)
+
+// smartctl é o utilitário do smartmontools usado para ler o SMART. A saída
+// em JSON exige a versão 7 ou mais recente.
+const smartctl = "smartctl"
+
+// Atributos SMART de discos ATA usados na avaliação de saúde
+const (
+	smartReallocatedSectors = 5
+	smartPendingSectors     = 197
+)
+
+// errSmartctlNotFound indica que o smartmontools não está instalado, uma
+// falha que não se resolve sozinha até a próxima leitura
+var errSmartctlNotFound = errors.New("smartctl não encontrado (instale o smartmontools ou desative o coletor disk_health)")
+
+// smartctlOutput são os campos lidos da saída de "smartctl --json -a"
+type smartctlOutput struct {
+	Smartctl struct {
+		ExitStatus int `json:"exit_status"`
+		Messages   []struct {
+			String string `json:"string"`
+		} `json:"messages"`
+	} `json:"smartctl"`
+	Devices []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"devices"`
+	ModelName    string `json:"model_name"`
+	SerialNumber string `json:"serial_number"`
+	SmartStatus  *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature struct {
+		Current int `json:"current"`
+	} `json:"temperature"`
+	PowerOnTime struct {
+		Hours uint64 `json:"hours"`
+	} `json:"power_on_time"`
+	ATAAttributes struct {
+		Table []struct {
+			ID  int `json:"id"`
+			Raw struct {
+				Value uint64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeHealth *struct {
+		PercentageUsed int    `json:"percentage_used"`
+		MediaErrors    uint64 `json:"media_errors"`
+	} `json:"nvme_smart_health_information_log"`
+}
+
+// runSmartctl executa o smartctl e decodifica a saída. Os bits 0 e 1 do
+// código de saída indicam que o comando não pôde ser executado; os demais
+// descrevem o estado do disco e não impedem a leitura.
+func runSmartctl(ctx context.Context, args ...string) (*smartctlOutput, error) {
+	data, err := exec.CommandContext(ctx, smartctl, append([]string{"--json"}, args...)...).Output()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, errSmartctlNotFound
+		}
+		return nil, err
+	}
+
+	out := &smartctlOutput{}
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, fmt.Errorf("saída inválida do smartctl: %v", err)
+	}
+	if out.Smartctl.ExitStatus&0x3 != 0 {
+		if len(out.Smartctl.Messages) > 0 {
+			return nil, errors.New(out.Smartctl.Messages[0].String)
+		}
+		return nil, fmt.Errorf("smartctl terminou com código %d", out.Smartctl.ExitStatus)
+	}
+	return out, nil
+}
+
+// smartReader guarda a última leitura do SMART. O inventário é coletado a
+// cada minuto, mas os discos só são lidos de novo a cada
+// diskhealth.SampleInterval, o intervalo do histórico de saúde: ler o SMART
+// com mais frequência acorda discos em repouso e repete os mesmos erros.
+type smartReader struct {
+	mu     sync.Mutex
+	readAt time.Time
+	disks  []model.DiskHealth
+	err    error
+	// readDisks lê os discos; é readDiskHealth fora dos testes
+	readDisks func(ctx context.Context, previous []model.DiskHealth) ([]model.DiskHealth, error)
+}
+
+var diskHealthReader = &smartReader{readDisks: readDiskHealth}
+
+// collectDiskHealth retorna a saúde de cada disco encontrado pelo smartctl
+func collectDiskHealth(ctx context.Context) (any, error) {
+	return diskHealthReader.read(ctx, time.Now())
+}
+
+// read retorna a última leitura, ou lê os discos de novo se ela é mais
+// antiga que diskhealth.SampleInterval. Só o smartctl ausente é guardado,
+// para não ser procurado a cada coleta; as demais falhas, como o tempo
+// limite da coleta esgotado, são tentadas de novo na coleta seguinte.
+func (r *smartReader) read(ctx context.Context, now time.Time) ([]model.DiskHealth, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.readAt.IsZero() && now.Sub(r.readAt) < diskhealth.SampleInterval {
+		return r.disks, r.err
+	}
+	disks, err := r.readDisks(ctx, r.disks)
+	if err != nil && !errors.Is(err, errSmartctlNotFound) {
+		return nil, err
+	}
+	r.readAt, r.disks, r.err = now, disks, err
+	return disks, err
+}
+
+// readDiskHealth lê o SMART de cada disco encontrado pelo smartctl. Discos
+// em repouso não são acordados e mantêm a leitura anterior, de previous;
+// discos que não respondem ao SMART (ex.: alguns adaptadores USB) são
+// ignorados. Se o tempo da coleta se esgota no meio da leitura, ela falha
+// inteira em vez de omitir os discos que faltavam.
+func readDiskHealth(ctx context.Context, previous []model.DiskHealth) ([]model.DiskHealth, error) {
+	scan, err := runSmartctl(ctx, "--scan-open")
+	if err != nil {
+		return nil, err
+	}
+
+	last := make(map[string]model.DiskHealth, len(previous))
+	for _, health := range previous {
+		last[health.Device] = health
+	}
+
+	disks := make([]model.DiskHealth, 0, len(scan.Devices))
+	for _, dev := range scan.Devices {
+		// Com "-n standby,0" o smartctl não lê um disco em repouso e
+		// termina sem erro, sem a avaliação SMART na saída
+		out, err := runSmartctl(ctx, "-a", "-n", "standby,0", "-d", dev.Type, dev.Name)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			logger.Default().With("component", "disk_health").Warn("Erro ao ler o SMART de %s: %v", dev.Name, err)
+			continue
+		}
+		if out.SmartStatus == nil {
+			// Disco em repouso ou sem suporte a SMART
+			if health, ok := last[path.Base(dev.Name)]; ok {
+				disks = append(disks, health)
+			}
+			continue
+		}
+
+		health := model.DiskHealth{
+			Device:       path.Base(dev.Name),
+			Model:        out.ModelName,
+			Serial:       out.SerialNumber,
+			Passed:       out.SmartStatus.Passed,
+			PowerOnHours: out.PowerOnTime.Hours,
+			TemperatureC: out.Temperature.Current,
+		}
+		for _, attr := range out.ATAAttributes.Table {
+			switch attr.ID {
+			case smartReallocatedSectors:
+				health.ReallocatedSectors = attr.Raw.Value
+			case smartPendingSectors:
+				health.PendingSectors = attr.Raw.Value
+			}
+		}
+		if nvme := out.NVMeHealth; nvme != nil {
+			health.MediaErrors = nvme.MediaErrors
+			health.PercentageUsed = nvme.PercentageUsed
+		}
+		disks = append(disks, health)
+	}
+	return disks, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/diskhealth"
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+func TestSmartReaderCachesPermanentErrorsOnly(t *testing.T) {
+	var reads int
+	var result error
+	r := &smartReader{readDisks: func(ctx context.Context, previous []model.DiskHealth) ([]model.DiskHealth, error) {
+		reads++
+		if result != nil {
+			return nil, result
+		}
+		return []model.DiskHealth{{Device: "sda", Passed: true}}, nil
+	}}
+	now := time.Now()
+
+	// Tempo esgotado: a coleta seguinte tenta de novo
+	result = context.DeadlineExceeded
+	if _, err := r.read(context.Background(), now); err == nil {
+		t.Fatal("read não repassou a falha")
+	}
+	result = nil
+	if disks, err := r.read(context.Background(), now.Add(time.Minute)); err != nil || len(disks) != 1 {
+		t.Fatalf("read após a falha = %v, %v", disks, err)
+	}
+	if reads != 2 {
+		t.Errorf("%d leituras, quer 2: falha temporária guardada", reads)
+	}
+
+	// Leitura bem-sucedida vale por SampleInterval
+	r.read(context.Background(), now.Add(2*time.Minute))
+	if reads != 2 {
+		t.Errorf("%d leituras, quer 2: leitura recente não reaproveitada", reads)
+	}
+
+	// smartctl ausente é guardado até a próxima leitura programada
+	result = errSmartctlNotFound
+	later := now.Add(time.Minute + diskhealth.SampleInterval)
+	r.read(context.Background(), later)
+	if _, err := r.read(context.Background(), later.Add(time.Minute)); err != errSmartctlNotFound {
+		t.Errorf("read = %v, quer smartctl ausente", err)
+	}
+	if reads != 3 {
+		t.Errorf("%d leituras, quer 3: smartctl ausente procurado de novo", reads)
+	}
+}
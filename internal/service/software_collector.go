@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/dev/falcon-agent/internal/model"
+	"github.com/dev/falcon-agent/pkg/logger"
+)
+
+// softwareSource lê os pacotes de um gerenciador. Os bancos de pacotes
+// têm milhares de entradas e mudam raramente, por isso a lista só é lida
+// de novo quando algum dos arquivos de referência muda.
+type softwareSource struct {
+	name string
+	// stamps são arquivos alterados pelo gerenciador a cada instalação. A
+	// origem é ignorada quando nenhum deles existe.
+	stamps []string
+	list   func(ctx context.Context) ([]model.SoftwarePackage, error)
+
+	mu     sync.Mutex
+	stamp  string
+	cached []model.SoftwarePackage
+}
+
+// packages retorna os pacotes da origem, usando a última leitura enquanto
+// os arquivos de referência não mudarem
+func (s *softwareSource) packages(ctx context.Context) ([]model.SoftwarePackage, error) {
+	stamp := ""
+	for _, name := range s.stamps {
+		if fi, err := os.Stat(name); err == nil {
+			stamp += fmt.Sprintf("%s:%d:%d;", name, fi.ModTime().UnixNano(), fi.Size())
+		}
+	}
+	if stamp == "" {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stamp == s.stamp {
+		return s.cached, nil
+	}
+	packages, err := s.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.stamp, s.cached = stamp, packages
+	return packages, nil
+}
+
+// collectSoftware retorna os programas instalados por todos os
+// gerenciadores de pacotes presentes, ordenados pelo nome. A falha de um
+// gerenciador não impede a listagem dos demais.
+func collectSoftware(ctx context.Context) (any, error) {
+	packages := make([]model.SoftwarePackage, 0)
+	var errs []error
+	for _, source := range softwareSources {
+		list, err := source.packages(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", source.name, err))
+			continue
+		}
+		packages = append(packages, list...)
+	}
+	if len(packages) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		logger.Default().With("component", "software").Warn("Erro ao listar os pacotes de %v", err)
+	}
+
+	sort.SliceStable(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Source < packages[j].Source
+	})
+	return packages, nil
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// Bancos de pacotes lidos pelo coletor de software
+const (
+	dpkgStatusFile = "/var/lib/dpkg/status"
+	dpkgInfoDir    = "/var/lib/dpkg/info"
+	flatpakAppDir  = "/var/lib/flatpak/app"
+	snapdSocket    = "/run/snapd.socket"
+)
+
+var softwareSources = []*softwareSource{
+	{name: model.SoftwareDpkg, stamps: []string{dpkgStatusFile}, list: readDpkgPackages},
+	{
+		name: model.SoftwareRPM,
+		stamps: []string{
+			"/var/lib/rpm/rpmdb.sqlite",
+			"/var/lib/rpm/Packages",
+			"/usr/lib/sysimage/rpm/rpmdb.sqlite",
+			"/usr/lib/sysimage/rpm/Packages.db",
+		},
+		list: readRPMPackages,
+	},
+	{name: model.SoftwareFlatpak, stamps: []string{"/var/lib/flatpak/.changed", flatpakAppDir}, list: readFlatpakApps},
+	{name: model.SoftwareSnap, stamps: []string{"/var/lib/snapd/state.json"}, list: readSnaps},
+}
+
+// readDpkgPackages lê os pacotes instalados do banco de status do dpkg. A
+// data de instalação é a da lista de arquivos do pacote, regravada a cada
+// instalação ou atualização.
+func readDpkgPackages(ctx context.Context) ([]model.SoftwarePackage, error) {
+	data, err := os.ReadFile(dpkgStatusFile)
+	if err != nil {
+		return nil, err
+	}
+	return parseDpkgStatus(string(data), dpkgInfoDir), nil
+}
+
+// parseDpkgStatus extrai os pacotes instalados do conteúdo do arquivo de
+// status do dpkg, buscando as listas de arquivos em infoDir
+func parseDpkgStatus(data, infoDir string) []model.SoftwarePackage {
+	var packages []model.SoftwarePackage
+	for _, stanza := range strings.Split(data, "\n\n") {
+		fields := make(map[string]string)
+		for _, line := range strings.Split(stanza, "\n") {
+			// Linhas que começam com espaço continuam o campo anterior
+			if line == "" || line[0] == ' ' || line[0] == '\t' {
+				continue
+			}
+			if key, value, ok := strings.Cut(line, ":"); ok {
+				fields[key] = strings.TrimSpace(value)
+			}
+		}
+		if !strings.HasSuffix(fields["Status"], " installed") {
+			continue
+		}
+
+		pkg := model.SoftwarePackage{
+			Name:    fields["Package"],
+			Version: fields["Version"],
+			Arch:    fields["Architecture"],
+			Vendor:  fields["Origin"],
+			Source:  model.SoftwareDpkg,
+		}
+		if pkg.Vendor == "" {
+			// Mantenedor sem o e-mail: "Ubuntu Developers <...>"
+			pkg.Vendor, _, _ = strings.Cut(fields["Maintainer"], " <")
+		}
+		for _, list := range []string{pkg.Name + ":" + pkg.Arch + ".list", pkg.Name + ".list"} {
+			if fi, err := os.Stat(filepath.Join(infoDir, list)); err == nil {
+				pkg.InstallDate = fi.ModTime().UTC()
+				break
+			}
+		}
+		packages = append(packages, pkg)
+	}
+	return packages
+}
+
+// readRPMPackages consulta o banco do RPM com o próprio rpm, pois o formato
+// do banco (Berkeley DB, NDB ou SQLite) varia entre as distribuições
+func readRPMPackages(ctx context.Context) ([]model.SoftwarePackage, error) {
+	out, err := exec.CommandContext(ctx, "rpm", "-qa", "--queryformat",
+		`%{NAME}\t%{VERSION}-%{RELEASE}\t%{ARCH}\t%{VENDOR}\t%{INSTALLTIME}\n`).Output()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar o rpm: %v", err)
+	}
+
+	var packages []model.SoftwarePackage
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		// As chaves GPG importadas aparecem como pacotes gpg-pubkey
+		if len(fields) != 5 || fields[0] == "gpg-pubkey" {
+			continue
+		}
+		for i, field := range fields {
+			if field == "(none)" {
+				fields[i] = ""
+			}
+		}
+
+		pkg := model.SoftwarePackage{
+			Name:    fields[0],
+			Version: fields[1],
+			Arch:    fields[2],
+			Vendor:  fields[3],
+			Source:  model.SoftwareRPM,
+		}
+		if ts, err := strconv.ParseInt(fields[4], 10, 64); err == nil {
+			pkg.InstallDate = time.Unix(ts, 0).UTC()
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, scanner.Err()
+}
+
+// flatpakMetainfo são os campos lidos do AppStream de um aplicativo Flatpak
+type flatpakMetainfo struct {
+	DeveloperName []string `xml:"developer_name"`
+	Developer     []string `xml:"developer>name"`
+	Releases      []struct {
+		Version string `xml:"version,attr"`
+	} `xml:"releases>release"`
+}
+
+// readFlatpakApps lista os aplicativos Flatpak instalados no sistema. A
+// versão e o desenvolvedor vêm do AppStream do aplicativo e a data é a da
+// última implantação (link "active").
+func readFlatpakApps(ctx context.Context) ([]model.SoftwarePackage, error) {
+	actives, err := filepath.Glob(filepath.Join(flatpakAppDir, "*", "*", "*", "active"))
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []model.SoftwarePackage
+	for _, active := range actives {
+		// app/<id>/<arquitetura>/<ramo>/active
+		branchDir := filepath.Dir(active)
+		archDir := filepath.Dir(branchDir)
+		id := filepath.Base(filepath.Dir(archDir))
+
+		pkg := model.SoftwarePackage{
+			Name:   id,
+			Arch:   filepath.Base(archDir),
+			Source: model.SoftwareFlatpak,
+		}
+		if fi, err := os.Lstat(active); err == nil {
+			pkg.InstallDate = fi.ModTime().UTC()
+		}
+
+		for _, name := range []string{"metainfo/" + id + ".metainfo.xml", "appdata/" + id + ".appdata.xml"} {
+			data, err := os.ReadFile(filepath.Join(active, "files", "share", name))
+			if err != nil {
+				continue
+			}
+			var meta flatpakMetainfo
+			if xml.Unmarshal(data, &meta) != nil {
+				continue
+			}
+			if len(meta.Releases) > 0 {
+				pkg.Version = meta.Releases[0].Version
+			}
+			if len(meta.DeveloperName) > 0 {
+				pkg.Vendor = meta.DeveloperName[0]
+			} else if len(meta.Developer) > 0 {
+				pkg.Vendor = meta.Developer[0]
+			}
+			break
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// snapdSnap são os campos lidos da lista de snaps da API do snapd
+type snapdSnap struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	InstallDate time.Time `json:"install-date"`
+	Publisher   struct {
+		DisplayName string `json:"display-name"`
+	} `json:"publisher"`
+}
+
+// readSnaps lista os snaps instalados pela API REST do snapd. Os snaps são
+// sempre da arquitetura da máquina.
+func readSnaps(ctx context.Context) ([]model.SoftwarePackage, error) {
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", snapdSocket)
+			},
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/v2/snaps", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar o snapd: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("snapd respondeu %s", resp.Status)
+	}
+	var body struct {
+		Result []snapdSnap `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("resposta inválida do snapd: %v", err)
+	}
+
+	packages := make([]model.SoftwarePackage, 0, len(body.Result))
+	for _, snap := range body.Result {
+		packages = append(packages, model.SoftwarePackage{
+			Name:        snap.Name,
+			Version:     snap.Version,
+			Arch:        runtime.GOARCH,
+			Vendor:      snap.Publisher.DisplayName,
+			InstallDate: snap.InstallDate.UTC(),
+			Source:      model.SoftwareSnap,
+		})
+	}
+	return packages, nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+const testDpkgStatus = `Package: bash
+Essential: yes
+Status: install ok installed
+Priority: required
+Architecture: amd64
+Maintainer: Ubuntu Developers <ubuntu-devel-discuss@lists.ubuntu.com>
+Version: 5.1-6ubuntu1
+Description: GNU Bourne Again SHell
+ Bash is an sh-compatible command language interpreter.
+ .
+ Status: linha de descrição que não é um campo
+
+Package: vim-tiny
+Status: deinstall ok config-files
+Architecture: amd64
+Version: 2:8.2.3995-1ubuntu2
+
+Package: google-chrome-stable
+Status: install ok installed
+Architecture: amd64
+Maintainer: Chrome Linux Team <chromium-dev@chromium.org>
+Origin: Google LLC
+Version: 120.0.6099.109-1
+`
+
+func TestParseDpkgStatus(t *testing.T) {
+	infoDir := t.TempDir()
+	installed := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	list := filepath.Join(infoDir, "bash.list")
+	if err := os.WriteFile(list, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(list, installed, installed); err != nil {
+		t.Fatal(err)
+	}
+
+	got := parseDpkgStatus(testDpkgStatus, infoDir)
+	want := []model.SoftwarePackage{
+		{Name: "bash", Version: "5.1-6ubuntu1", Arch: "amd64", Vendor: "Ubuntu Developers",
+			Source: model.SoftwareDpkg, InstallDate: installed},
+		{Name: "google-chrome-stable", Version: "120.0.6099.109-1", Arch: "amd64", Vendor: "Google LLC",
+			Source: model.SoftwareDpkg},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseDpkgStatus = %+v, quer %d pacotes", got, len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("pacote %d = %+v, quer %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package service
+
+// softwareSources é vazio fora do Linux: os gerenciadores de pacotes
+// suportados (dpkg, RPM, Flatpak e Snap) são todos do Linux
+var softwareSources []*softwareSource
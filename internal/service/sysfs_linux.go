@@ -0,0 +1,34 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Diretórios do sysfs com os dispositivos PCI e de bloco
+const (
+	sysfsPCIPath   = "/sys/bus/pci/devices"
+	sysfsBlockPath = "/sys/class/block"
+)
+
+// readSysfsAttr lê um atributo do sysfs sem espaços e quebras de linha,
+// ou retorna vazio se ele não existir
+func readSysfsAttr(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// sysfsExists indica se o caminho existe no sysfs
+func sysfsExists(path ...string) bool {
+	_, err := os.Stat(filepath.Join(path...))
+	return err == nil
+}
+
+// blockPartition indica se o dispositivo de bloco é uma partição de disco
+func blockPartition(name string) bool {
+	return sysfsExists(sysfsBlockPath, name, "partition")
+}
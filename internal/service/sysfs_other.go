@@ -0,0 +1,9 @@
+//go:build !linux
+
+package service
+
+// blockPartition não é suportado fora do Linux: os contadores de disco do
+// gopsutil nesses sistemas já são por disco
+func blockPartition(name string) bool {
+	return false
+}
@@ -0,0 +1,251 @@
+package spool
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// recordExt é a extensão dos registros confirmados em disco; arquivos
+// temporários de uma escrita interrompida são descartados na abertura
+const (
+	recordExt = ".rec"
+	tmpExt    = ".tmp"
+)
+
+// Options limita o tamanho da fila. Quando um limite é ultrapassado, os
+// registros mais antigos são descartados. Zero significa sem limite.
+type Options struct {
+	MaxBytes   int64
+	MaxRecords int
+}
+
+// record é um registro da fila. A chave, opcional, faz parte do nome do
+// arquivo (<seq>-<chave>.rec) e identifica registros que se substituem.
+type record struct {
+	seq  uint64
+	key  string
+	size int64
+}
+
+// Spool é uma fila FIFO persistente em disco. Cada registro é um arquivo
+// cujo nome é um número de sequência crescente, seguido da chave quando o
+// registro foi gravado com Replace, gravado de forma atômica (arquivo
+// temporário, fsync e rename), de modo que a fila sobrevive a reinícios e
+// quedas do processo sem registros parciais.
+type Spool struct {
+	mu      sync.Mutex
+	dir     string
+	opts    Options
+	records []record
+	size    int64
+	nextSeq uint64
+}
+
+// Open abre (ou cria) a fila no diretório informado
+func Open(dir string, opts Options) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório da fila: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler diretório da fila: %v", err)
+	}
+
+	s := &Spool{dir: dir, opts: opts, nextSeq: 1}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, tmpExt) {
+			os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		if !strings.HasSuffix(name, recordExt) {
+			continue
+		}
+		num, key, _ := strings.Cut(strings.TrimSuffix(name, recordExt), "-")
+		seq, err := strconv.ParseUint(num, 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		s.records = append(s.records, record{seq: seq, key: key, size: info.Size()})
+		s.size += info.Size()
+		if seq >= s.nextSeq {
+			s.nextSeq = seq + 1
+		}
+	}
+
+	sort.Slice(s.records, func(i, j int) bool { return s.records[i].seq < s.records[j].seq })
+	return s, nil
+}
+
+// Push grava um registro no final da fila, descartando os mais antigos se
+// a fila ultrapassar os limites. Retorna quantos registros foram descartados.
+func (s *Spool) Push(data []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.push("", data)
+}
+
+// Replace grava um registro no final da fila e remove os registros
+// pendentes com a mesma chave, de modo que a fila guarda apenas o mais
+// recente deles. Retorna quantos registros foram descartados pelos limites.
+func (s *Spool) Replace(key string, data []byte) (int, error) {
+	if key == "" || strings.ContainsAny(key, "-./\\") {
+		return 0, fmt.Errorf("chave de registro inválida: %q", key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	evicted, err := s.push(key, data)
+	if err != nil {
+		return 0, err
+	}
+	last := len(s.records) - 1
+	kept := s.records[:0]
+	for i, r := range s.records {
+		if r.key == key && i != last {
+			os.Remove(s.filename(r))
+			s.size -= r.size
+			continue
+		}
+		kept = append(kept, r)
+	}
+	s.records = kept
+	return evicted, nil
+}
+
+func (s *Spool) push(key string, data []byte) (int, error) {
+	r := record{seq: s.nextSeq, key: key, size: int64(len(data))}
+	filename := s.filename(r)
+	tmp := filename + tmpExt
+
+	if err := writeSync(tmp, data); err != nil {
+		os.Remove(tmp)
+		return 0, fmt.Errorf("erro ao gravar registro na fila: %v", err)
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		os.Remove(tmp)
+		return 0, fmt.Errorf("erro ao gravar registro na fila: %v", err)
+	}
+	syncDir(s.dir)
+
+	s.nextSeq++
+	s.records = append(s.records, r)
+	s.size += r.size
+
+	return s.evict(), nil
+}
+
+// evict remove os registros mais antigos até a fila caber nos limites,
+// mantendo sempre ao menos o registro mais recente
+func (s *Spool) evict() int {
+	evicted := 0
+	for len(s.records) > 1 &&
+		((s.opts.MaxRecords > 0 && len(s.records) > s.opts.MaxRecords) ||
+			(s.opts.MaxBytes > 0 && s.size > s.opts.MaxBytes)) {
+		s.removeFirst()
+		evicted++
+	}
+	return evicted
+}
+
+// Peek retorna o registro mais antigo sem removê-lo. O número de
+// sequência deve ser passado a Remove depois que o registro for entregue.
+func (s *Spool) Peek() (uint64, []byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.records) == 0 {
+		return 0, nil, false, nil
+	}
+
+	seq := s.records[0].seq
+	data, err := os.ReadFile(s.filename(s.records[0]))
+	if err != nil {
+		return seq, nil, true, fmt.Errorf("erro ao ler registro %d da fila: %v", seq, err)
+	}
+	return seq, data, true, nil
+}
+
+// Remove confirma a entrega de um registro, apagando-o da fila
+func (s *Spool) Remove(seq uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, r := range s.records {
+		if r.seq != seq {
+			continue
+		}
+		if err := os.Remove(s.filename(r)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		s.size -= r.size
+		s.records = append(s.records[:i], s.records[i+1:]...)
+		return nil
+	}
+	return nil
+}
+
+func (s *Spool) removeFirst() {
+	r := s.records[0]
+	os.Remove(s.filename(r))
+	s.size -= r.size
+	s.records = s.records[1:]
+}
+
+// Len retorna o número de registros na fila
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records)
+}
+
+// Size retorna o total de bytes dos registros na fila
+func (s *Spool) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+func (s *Spool) filename(r record) string {
+	if r.key == "" {
+		return filepath.Join(s.dir, fmt.Sprintf("%020d%s", r.seq, recordExt))
+	}
+	return filepath.Join(s.dir, fmt.Sprintf("%020d-%s%s", r.seq, r.key, recordExt))
+}
+
+// writeSync grava o arquivo e força os dados para o disco
+func writeSync(filename string, data []byte) error {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// syncDir garante que o rename do registro foi persistido. Falhas são
+// ignoradas porque nem todo sistema de arquivos permite fsync em diretórios.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
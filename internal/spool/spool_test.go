@@ -0,0 +1,152 @@
+package spool
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func openTestSpool(t *testing.T, dir string, opts Options) *Spool {
+	t.Helper()
+	s, err := Open(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func push(t *testing.T, s *Spool, records ...string) int {
+	t.Helper()
+	evicted := 0
+	for _, r := range records {
+		n, err := s.Push([]byte(r))
+		if err != nil {
+			t.Fatal(err)
+		}
+		evicted += n
+	}
+	return evicted
+}
+
+// drain esvazia a fila e retorna os registros na ordem de entrega
+func drain(t *testing.T, s *Spool) []string {
+	t.Helper()
+	var records []string
+	for {
+		seq, data, ok, err := s.Peek()
+		if !ok {
+			return records
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, string(data))
+		if err := s.Remove(seq); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func equal(a, b []string) bool {
+	return strings.Join(a, ",") == strings.Join(b, ",")
+}
+
+func TestSpoolEvictsOldest(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"por registros", Options{MaxRecords: 2}, []string{"ccc", "dddd"}},
+		{"por bytes", Options{MaxBytes: 6}, []string{"dddd"}},
+		{"por bytes com folga", Options{MaxBytes: 9}, []string{"bb", "ccc", "dddd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openTestSpool(t, t.TempDir(), tt.opts)
+			evicted := push(t, s, "a", "bb", "ccc", "dddd")
+			if evicted != 4-len(tt.want) {
+				t.Errorf("%d registros descartados, quer %d", evicted, 4-len(tt.want))
+			}
+			if got := drain(t, s); !equal(got, tt.want) {
+				t.Errorf("fila = %v, quer %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpoolKeepsNewestRecordOverLimit(t *testing.T) {
+	s := openTestSpool(t, t.TempDir(), Options{MaxBytes: 2})
+	push(t, s, "a", "grande demais")
+	if got := drain(t, s); !equal(got, []string{"grande demais"}) {
+		t.Errorf("fila = %v, quer só o registro mais recente", got)
+	}
+}
+
+func TestSpoolReopen(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestSpool(t, dir, Options{})
+	push(t, s, "a", "b", "c")
+	seq, _, _, _ := s.Peek()
+	if err := s.Remove(seq); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restos de uma escrita interrompida são descartados na abertura
+	tmp := filepath.Join(dir, "00000000000000000009.rec.tmp")
+	if err := os.WriteFile(tmp, []byte("parcial"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s = openTestSpool(t, dir, Options{})
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Errorf("arquivo temporário não foi removido: %v", err)
+	}
+	if s.Len() != 2 || s.Size() != 2 {
+		t.Errorf("fila reaberta com %d registros e %d bytes, quer 2 e 2", s.Len(), s.Size())
+	}
+
+	// Os registros novos continuam a sequência, depois dos antigos
+	push(t, s, "d")
+	if s.nextSeq != 5 {
+		t.Errorf("nextSeq = %d, quer 5", s.nextSeq)
+	}
+	if got := drain(t, s); !equal(got, []string{"b", "c", "d"}) {
+		t.Errorf("fila = %v, quer [b c d]", got)
+	}
+}
+
+func TestSpoolReplace(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestSpool(t, dir, Options{})
+	push(t, s, "a")
+	if _, err := s.Replace("inventory", []byte("i1")); err != nil {
+		t.Fatal(err)
+	}
+	push(t, s, "b")
+	if _, err := s.Replace("inventory", []byte("i2")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "00000000000000000004-inventory.rec")); err != nil {
+		t.Errorf("registro com chave não gravado como <seq>-<chave>.rec: %v", err)
+	}
+	if s.Len() != 3 || s.Size() != 4 {
+		t.Errorf("fila com %d registros e %d bytes, quer 3 e 4", s.Len(), s.Size())
+	}
+
+	// A chave sobrevive à reabertura e continua substituindo o registro
+	s = openTestSpool(t, dir, Options{})
+	if _, err := s.Replace("inventory", []byte("i3")); err != nil {
+		t.Fatal(err)
+	}
+	if got := drain(t, s); !equal(got, []string{"a", "b", "i3"}) {
+		t.Errorf("fila = %v, quer [a b i3]", got)
+	}
+
+	for _, key := range []string{"", "a-b", "../x", "a.b"} {
+		if _, err := s.Replace(key, []byte("x")); err == nil {
+			t.Errorf("Replace aceitou a chave %q", key)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package usb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// interfaceWaitTimeout limita a espera pelas interfaces de um dispositivo
+// recém-conectado, criadas pelo kernel logo após o evento de conexão
+const interfaceWaitTimeout = 2 * time.Second
+
+// Decision registra a decisão da política para um dispositivo
+type Decision struct {
+	Time   time.Time       `json:"time"`
+	Device model.USBDevice `json:"device"`
+	Action Action          `json:"action"`
+	Mode   Mode            `json:"mode"`
+	// Rule é o índice da regra que decidiu, ou -1 para a ação padrão
+	Rule    int    `json:"rule"`
+	Comment string `json:"comment,omitempty"`
+	// Enforced indica que a decisão foi aplicada no sysfs
+	Enforced bool   `json:"enforced"`
+	Error    string `json:"error,omitempty"`
+}
+
+// AuditLog grava as decisões da política em JSON, uma por linha
+type AuditLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenAuditLog abre (ou cria) o arquivo de auditoria para escrita no final
+func OpenAuditLog(filename string) (*AuditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de auditoria: %v", err)
+	}
+
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir log de auditoria: %v", err)
+	}
+	return &AuditLog{file: file}, nil
+}
+
+// Record grava uma decisão no log de auditoria
+func (l *AuditLog) Record(decision Decision) error {
+	data, err := json.Marshal(decision)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close fecha o arquivo de auditoria
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// Enforcer aplica a política aos dispositivos escrevendo o atributo
+// "authorized" do sysfs e registra cada decisão na auditoria
+type Enforcer struct {
+	mu     sync.RWMutex
+	policy *Policy
+	audit  *AuditLog
+	// blocked são os dispositivos desautorizados por este aplicador, pela
+	// porta, para que possam ser liberados se a política deixar de valer
+	blocked map[string]model.USBDevice
+}
+
+// NewEnforcer cria um aplicador para a política informada e ajusta a
+// autorização automática dos barramentos ao modo da política
+func NewEnforcer(policy *Policy, audit *AuditLog) (*Enforcer, error) {
+	e := &Enforcer{
+		policy:  policy,
+		audit:   audit,
+		blocked: make(map[string]model.USBDevice),
+	}
+	return e, applyBusDefault(policy.Mode)
+}
+
+// Policy retorna a política em vigor
+func (e *Enforcer) Policy() *Policy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.policy
+}
+
+// SetPolicy troca a política usada nas próximas decisões e ajusta a
+// autorização automática dos barramentos ao novo modo. Fora do modo
+// enforce, os dispositivos bloqueados antes são autorizados novamente.
+func (e *Enforcer) SetPolicy(policy *Policy) error {
+	e.mu.Lock()
+	e.policy = policy
+	e.mu.Unlock()
+
+	var errs []error
+	if policy.Mode != ModeEnforce {
+		errs = append(errs, e.release("política USB em modo monitor"))
+	}
+	errs = append(errs, applyBusDefault(policy.Mode))
+	return errors.Join(errs...)
+}
+
+// Release autoriza novamente os dispositivos bloqueados por este aplicador
+// e restaura a autorização automática dos barramentos. Deve ser chamado
+// quando a política é removida, para que nenhum dispositivo continue
+// bloqueado sem uma regra que o justifique.
+func (e *Enforcer) Release() error {
+	return errors.Join(e.release("política USB removida"), setBusAuthorizedDefault(true))
+}
+
+// release autoriza os dispositivos bloqueados e registra cada liberação na
+// auditoria com o motivo informado
+func (e *Enforcer) release(reason string) error {
+	e.mu.Lock()
+	blocked := e.blocked
+	e.blocked = make(map[string]model.USBDevice)
+	e.mu.Unlock()
+
+	var errs []error
+	for port, dev := range blocked {
+		decision := Decision{
+			Time:    time.Now(),
+			Device:  dev,
+			Action:  ActionAllow,
+			Mode:    ModeEnforce,
+			Rule:    -1,
+			Comment: reason,
+		}
+		if err := setAuthorized(filepath.Join(SysfsDevicesPath, port), true); err != nil {
+			decision.Error = err.Error()
+			errs = append(errs, err)
+		} else {
+			decision.Enforced = true
+		}
+		if e.audit != nil {
+			if err := e.audit.Record(decision); err != nil {
+				errs = append(errs, fmt.Errorf("erro ao gravar auditoria: %v", err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AuthorizeHub autoriza um hub recém-conectado em modo enforce, em que o
+// kernel não autoriza sozinho os dispositivos novos
+func (e *Enforcer) AuthorizeHub(dir string) error {
+	if e.Policy().Mode != ModeEnforce {
+		return nil
+	}
+	return setAuthorized(dir, true)
+}
+
+// Apply avalia o dispositivo, aplica a decisão quando a política está em
+// modo enforce e registra o resultado na auditoria
+func (e *Enforcer) Apply(dev model.USBDevice) Decision {
+	policy := e.Policy()
+	dir := filepath.Join(SysfsDevicesPath, dev.Port)
+
+	if policy.NeedsInterfaceClasses() && len(dev.InterfaceClasses) == 0 {
+		dev.InterfaceClasses = waitInterfaceClasses(dir, interfaceWaitTimeout)
+	}
+
+	action, rule := policy.Evaluate(dev)
+	decision := Decision{
+		Time:   time.Now(),
+		Device: dev,
+		Action: action,
+		Mode:   policy.Mode,
+		Rule:   rule,
+	}
+	if rule >= 0 {
+		decision.Comment = policy.Rules[rule].Comment
+	}
+
+	if policy.Mode == ModeEnforce {
+		if dev.Port == "" {
+			decision.Error = "porta do dispositivo desconhecida"
+		} else if err := setAuthorized(dir, action == ActionAllow); err != nil {
+			decision.Error = err.Error()
+		} else {
+			decision.Enforced = true
+			e.mu.Lock()
+			if action == ActionBlock {
+				e.blocked[dev.Port] = dev
+			} else {
+				delete(e.blocked, dev.Port)
+			}
+			e.mu.Unlock()
+		}
+	}
+
+	if e.audit != nil {
+		if err := e.audit.Record(decision); err != nil && decision.Error == "" {
+			decision.Error = fmt.Sprintf("erro ao gravar auditoria: %v", err)
+		}
+	}
+
+	return decision
+}
+
+// applyBusDefault desliga, em modo enforce, a autorização automática dos
+// dispositivos novos, para que nenhum driver se associe a eles antes da
+// decisão da política; nos demais modos o padrão do kernel é restaurado
+func applyBusDefault(mode Mode) error {
+	if mode != ModeEnforce {
+		return setBusAuthorizedDefault(true)
+	}
+	if err := setBusAuthorizedDefault(false); err != nil {
+		return err
+	}
+	return authorizeHubs()
+}
+
+// waitInterfaceClasses aguarda o kernel criar as interfaces do dispositivo
+func waitInterfaceClasses(dir string, timeout time.Duration) []string {
+	deadline := time.Now().Add(timeout)
+	for {
+		classes := ReadInterfaceClasses(dir)
+		if len(classes) > 0 || time.Now().After(deadline) {
+			return classes
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// setAuthorized autoriza ou desautoriza o dispositivo no sysfs, escrevendo
+// apenas quando o estado atual é diferente
+func setAuthorized(dir string, authorized bool) error {
+	value := "0"
+	if authorized {
+		value = "1"
+	}
+
+	if readAttr(dir, "authorized") == value {
+		return nil
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "authorized"), []byte(value), 0644); err != nil {
+		return fmt.Errorf("erro ao alterar autorização do dispositivo %s: %v", filepath.Base(dir), err)
+	}
+	return nil
+}
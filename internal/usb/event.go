@@ -0,0 +1,26 @@
+package usb
+
+import (
+	"time"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// EventType indica se o dispositivo foi conectado ou desconectado
+type EventType string
+
+const (
+	DeviceAttached EventType = "attached"
+	DeviceDetached EventType = "detached"
+)
+
+// Event representa a conexão ou desconexão de um dispositivo USB
+type Event struct {
+	Type   EventType       `json:"type"`
+	Device model.USBDevice `json:"device"`
+	Time   time.Time       `json:"time"`
+	// Port é o caminho da porta no formato do sysfs (ex.: "1-2.3")
+	Port string `json:"port"`
+	// Decision é a decisão da política USB, quando há uma política ativa
+	Decision *Decision `json:"decision,omitempty"`
+}
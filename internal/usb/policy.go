@@ -0,0 +1,177 @@
+package usb
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// Action é a decisão da política para um dispositivo
+type Action string
+
+const (
+	ActionAllow Action = "allow"
+	ActionBlock Action = "block"
+)
+
+// Mode define se as decisões são aplicadas ou apenas registradas
+type Mode string
+
+const (
+	// ModeEnforce autoriza ou bloqueia o dispositivo pelo sysfs
+	ModeEnforce Mode = "enforce"
+	// ModeMonitor apenas registra no log de auditoria o que seria feito
+	ModeMonitor Mode = "monitor"
+)
+
+// interfaceClassNames traduz os nomes aceitos nas regras para as classes USB
+var interfaceClassNames = map[string]string{
+	"audio":           "01",
+	"cdc":             "02",
+	"hid":             "03",
+	"physical":        "05",
+	"image":           "06",
+	"printer":         "07",
+	"mass_storage":    "08",
+	"hub":             "09",
+	"cdc_data":        "0a",
+	"smart_card":      "0b",
+	"video":           "0e",
+	"wireless":        "e0",
+	"vendor_specific": "ff",
+}
+
+// Rule descreve um conjunto de dispositivos e a ação aplicada a eles.
+// Campos vazios aceitam qualquer valor.
+type Rule struct {
+	Action    Action `json:"action"`
+	VendorID  string `json:"vendor_id,omitempty"`
+	ProductID string `json:"product_id,omitempty"`
+	Serial    string `json:"serial,omitempty"`
+	// InterfaceClass aceita a classe em hexadecimal ("08") ou pelo nome ("mass_storage")
+	InterfaceClass string `json:"interface_class,omitempty"`
+	// Port aceita padrões no formato de path.Match (ex.: "1-2.*")
+	Port    string `json:"port,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// Policy é a política de controle de dispositivos USB. As regras são
+// avaliadas em ordem e a primeira que casar decide; sem regra, vale Default.
+type Policy struct {
+	Mode    Mode   `json:"mode"`
+	Default Action `json:"default"`
+	Rules   []Rule `json:"rules"`
+}
+
+// LoadPolicy lê e valida uma política em JSON
+func LoadPolicy(filename string) (*Policy, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("erro ao ler política USB %s: %v", filename, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("política USB %s inválida: %v", filename, err)
+	}
+	return policy, nil
+}
+
+// Validate verifica a política e normaliza os identificadores das regras
+func (p *Policy) Validate() error {
+	if p.Mode == "" {
+		p.Mode = ModeMonitor
+	}
+	if p.Mode != ModeEnforce && p.Mode != ModeMonitor {
+		return fmt.Errorf("mode: valor inválido %q", p.Mode)
+	}
+
+	if p.Default == "" {
+		p.Default = ActionAllow
+	}
+	if p.Default != ActionAllow && p.Default != ActionBlock {
+		return fmt.Errorf("default: ação inválida %q", p.Default)
+	}
+
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Action != ActionAllow && rule.Action != ActionBlock {
+			return fmt.Errorf("rules[%d].action: ação inválida %q", i, rule.Action)
+		}
+		if rule.Port != "" {
+			if _, err := path.Match(rule.Port, ""); err != nil {
+				return fmt.Errorf("rules[%d].port: padrão inválido %q", i, rule.Port)
+			}
+		}
+
+		rule.VendorID = strings.ToLower(rule.VendorID)
+		rule.ProductID = strings.ToLower(rule.ProductID)
+		class := strings.ToLower(rule.InterfaceClass)
+		if name, ok := interfaceClassNames[class]; ok {
+			class = name
+		}
+		rule.InterfaceClass = class
+	}
+
+	return nil
+}
+
+// Evaluate retorna a ação para o dispositivo e o índice da regra que
+// decidiu, ou -1 quando valeu a ação padrão
+func (p *Policy) Evaluate(dev model.USBDevice) (Action, int) {
+	for i, rule := range p.Rules {
+		if rule.Matches(dev) {
+			return rule.Action, i
+		}
+	}
+	return p.Default, -1
+}
+
+// NeedsInterfaceClasses indica se alguma regra depende das interfaces do
+// dispositivo, que só existem no sysfs depois da configuração pelo kernel
+func (p *Policy) NeedsInterfaceClasses() bool {
+	for _, rule := range p.Rules {
+		if rule.InterfaceClass != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches indica se o dispositivo atende a todos os critérios da regra
+func (r Rule) Matches(dev model.USBDevice) bool {
+	if r.VendorID != "" && r.VendorID != strings.ToLower(dev.VendorID) {
+		return false
+	}
+	if r.ProductID != "" && r.ProductID != strings.ToLower(dev.ProductID) {
+		return false
+	}
+	if r.Serial != "" && r.Serial != dev.Serial {
+		return false
+	}
+	if r.Port != "" {
+		if ok, _ := path.Match(r.Port, dev.Port); !ok {
+			return false
+		}
+	}
+	if r.InterfaceClass != "" {
+		found := false
+		for _, class := range dev.InterfaceClasses {
+			if strings.ToLower(class) == r.InterfaceClass {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package usb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+func TestPolicyEvaluate(t *testing.T) {
+	policy := &Policy{
+		Mode:    ModeEnforce,
+		Default: ActionBlock,
+		Rules: []Rule{
+			{Action: ActionBlock, InterfaceClass: "mass_storage"},
+			{Action: ActionAllow, VendorID: "046D"},
+			{Action: ActionAllow, VendorID: "0781", ProductID: "5581", Serial: "4C530001"},
+			{Action: ActionAllow, Port: "1-2.*"},
+			{Action: ActionAllow, InterfaceClass: "03"},
+		},
+	}
+	if err := policy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		dev    model.USBDevice
+		action Action
+		rule   int
+	}{
+		{"pendrive bloqueado pela classe", model.USBDevice{VendorID: "046d", InterfaceClasses: []string{"08"}}, ActionBlock, 0},
+		{"fornecedor sem diferenciar maiúsculas", model.USBDevice{VendorID: "046d", ProductID: "c52b"}, ActionAllow, 1},
+		{"serial exato", model.USBDevice{VendorID: "0781", ProductID: "5581", Serial: "4C530001"}, ActionAllow, 2},
+		{"serial diferente", model.USBDevice{VendorID: "0781", ProductID: "5581", Serial: "outro"}, ActionBlock, -1},
+		{"porta pelo padrão", model.USBDevice{VendorID: "1234", Port: "1-2.3"}, ActionAllow, 3},
+		{"porta fora do padrão", model.USBDevice{VendorID: "1234", Port: "1-3"}, ActionBlock, -1},
+		{"classe em hexadecimal", model.USBDevice{VendorID: "1234", InterfaceClasses: []string{"02", "03"}}, ActionAllow, 4},
+		{"sem regra vale o padrão", model.USBDevice{VendorID: "1234"}, ActionBlock, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, rule := policy.Evaluate(tt.dev)
+			if action != tt.action || rule != tt.rule {
+				t.Errorf("Evaluate = %s, %d; quer %s, %d", action, rule, tt.action, tt.rule)
+			}
+		})
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	policy := &Policy{}
+	if err := policy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if policy.Mode != ModeMonitor || policy.Default != ActionAllow {
+		t.Errorf("padrões = %s, %s; quer %s, %s", policy.Mode, policy.Default, ModeMonitor, ActionAllow)
+	}
+
+	invalid := []*Policy{
+		{Mode: "bloquear"},
+		{Default: "negar"},
+		{Rules: []Rule{{Action: "negar"}}},
+		{Rules: []Rule{{Action: ActionBlock, Port: "1-["}}},
+	}
+	for _, p := range invalid {
+		if err := p.Validate(); err == nil {
+			t.Errorf("Validate(%+v) não falhou", p)
+		}
+	}
+}
+
+func TestParseInterfaceClasses(t *testing.T) {
+	data := []byte{
+		// Descritor do dispositivo (classe 00: definida nas interfaces)
+		18, 1, 0x00, 0x02, 0x00, 0x00, 0x00, 64, 0x81, 0x07, 0x81, 0x55, 0x00, 0x01, 1, 2, 3, 1,
+		// Configuração
+		9, 2, 32, 0, 1, 1, 0, 0x80, 50,
+		// Interface de armazenamento em massa
+		9, 4, 0, 0, 2, 0x08, 0x06, 0x50, 0,
+		// Endpoints
+		7, 5, 0x81, 0x02, 0x00, 0x02, 0,
+		7, 5, 0x02, 0x02, 0x00, 0x02, 0,
+		// Segunda interface com a mesma classe e uma HID
+		9, 4, 1, 0, 0, 0x08, 0x06, 0x50, 0,
+		9, 4, 2, 0, 1, 0x03, 0x01, 0x01, 0,
+	}
+	if got, want := parseInterfaceClasses(data), []string{"08", "03"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseInterfaceClasses = %v, quer %v", got, want)
+	}
+
+	// Descritor truncado não pode travar nem gerar classes falsas
+	if got := parseInterfaceClasses([]byte{9, 4, 0}); got != nil {
+		t.Errorf("descritor truncado = %v", got)
+	}
+}
@@ -0,0 +1,168 @@
+package usb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// SysfsDevicesPath é o diretório do sysfs com os dispositivos USB
+const SysfsDevicesPath = "/sys/bus/usb/devices"
+
+// hubClass é a classe USB dos hubs, que não são reportados
+const hubClass = "09"
+
+// readAttr lê um atributo do sysfs sem espaços e quebras de linha
+func readAttr(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// ReadDevice lê as informações de um dispositivo a partir do seu
+// diretório no sysfs
+func ReadDevice(dir string) (model.USBDevice, error) {
+	vendorID := readAttr(dir, "idVendor")
+	productID := readAttr(dir, "idProduct")
+	if vendorID == "" || productID == "" {
+		return model.USBDevice{}, fmt.Errorf("dispositivo USB inválido em %s", dir)
+	}
+
+	manufacturer := readAttr(dir, "manufacturer")
+	product := readAttr(dir, "product")
+
+	var name string
+	if manufacturer != "" && product != "" {
+		name = fmt.Sprintf("%s %s", manufacturer, product)
+	} else {
+		name = fmt.Sprintf("USB Device %s:%s", vendorID, productID)
+	}
+
+	return model.USBDevice{
+		VendorID:  vendorID,
+		ProductID: productID,
+		Name:      name,
+		Serial:    readAttr(dir, "serial"),
+		Port:      filepath.Base(dir),
+
+		InterfaceClasses: ReadInterfaceClasses(dir),
+	}, nil
+}
+
+// Tipos de descritor USB usados na leitura das interfaces
+const (
+	descriptorInterface = 4
+	// interfaceClassOffset é a posição de bInterfaceClass no descritor
+	// de interface
+	interfaceClassOffset = 5
+)
+
+// ReadInterfaceClasses lê as classes das interfaces do dispositivo, sem
+// repetições. As classes vêm do arquivo descriptors, que guarda os
+// descritores lidos na enumeração e continua disponível quando o
+// dispositivo está desautorizado e o kernel remove os diretórios das
+// interfaces. Por isso são consideradas as interfaces de todas as
+// configurações, e não só as da configuração ativa.
+func ReadInterfaceClasses(dir string) []string {
+	if data, err := os.ReadFile(filepath.Join(dir, "descriptors")); err == nil {
+		if classes := parseInterfaceClasses(data); len(classes) > 0 {
+			return classes
+		}
+	}
+
+	// Sem o arquivo descriptors, as interfaces da configuração ativa
+	interfaces, err := filepath.Glob(filepath.Join(dir, filepath.Base(dir)+":*"))
+	if err != nil {
+		return nil
+	}
+
+	var classes []string
+	seen := make(map[string]bool)
+	for _, iface := range interfaces {
+		class := readAttr(iface, "bInterfaceClass")
+		if class != "" && !seen[class] {
+			seen[class] = true
+			classes = append(classes, class)
+		}
+	}
+	return classes
+}
+
+// parseInterfaceClasses percorre os descritores brutos (o descritor do
+// dispositivo seguido dos descritores de cada configuração) e retorna as
+// classes dos descritores de interface, em hexadecimal como no sysfs
+func parseInterfaceClasses(data []byte) []string {
+	var classes []string
+	seen := make(map[string]bool)
+	for len(data) >= 2 {
+		length := int(data[0])
+		if length < 2 || length > len(data) {
+			break
+		}
+		if data[1] == descriptorInterface && length > interfaceClassOffset {
+			class := fmt.Sprintf("%02x", data[interfaceClassOffset])
+			if !seen[class] {
+				seen[class] = true
+				classes = append(classes, class)
+			}
+		}
+		data = data[length:]
+	}
+	return classes
+}
+
+// setBusAuthorizedDefault define, pelo atributo authorized_default dos
+// root hubs, se os dispositivos conectados daqui em diante são
+// autorizados pelo kernel antes da decisão da política
+func setBusAuthorizedDefault(authorized bool) error {
+	value := "0"
+	if authorized {
+		value = "1"
+	}
+
+	buses, err := filepath.Glob(filepath.Join(SysfsDevicesPath, "usb*"))
+	if err != nil {
+		return err
+	}
+	for _, bus := range buses {
+		if readAttr(bus, "authorized_default") == value {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(bus, "authorized_default"), []byte(value), 0644); err != nil {
+			return fmt.Errorf("erro ao alterar authorized_default de %s: %v", filepath.Base(bus), err)
+		}
+	}
+	return nil
+}
+
+// authorizeHubs autoriza os hubs desautorizados. Com authorized_default
+// em 0 o kernel também deixa de autorizar os hubs externos, que não
+// passam pela política, e os dispositivos ligados a eles nem aparecem.
+func authorizeHubs() error {
+	entries, err := os.ReadDir(SysfsDevicesPath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ":") || strings.HasPrefix(entry.Name(), "usb") {
+			continue
+		}
+		dir := filepath.Join(SysfsDevicesPath, entry.Name())
+		if isHub(dir) {
+			if err := setAuthorized(dir, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isHub indica se o diretório do sysfs corresponde a um hub USB
+func isHub(dir string) bool {
+	return readAttr(dir, "bDeviceClass") == hubClass
+}
@@ -0,0 +1,218 @@
+package usb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// ueventBufferSize comporta o maior uevent enviado pelo kernel
+const ueventBufferSize = 8192
+
+// Watcher recebe os uevents do kernel via netlink e emite eventos de
+// conexão e desconexão de dispositivos USB
+type Watcher struct {
+	file   *os.File
+	events chan Event
+
+	// devices guarda os dispositivos conectados pelo DEVPATH, pois no
+	// evento de remoção os atributos do sysfs já não existem
+	mu      sync.Mutex
+	devices map[string]model.USBDevice
+	onHub   func(dir string)
+
+	closeOnce sync.Once
+}
+
+// NewWatcher abre o socket netlink de uevents do kernel e começa a
+// monitorar os dispositivos USB
+func NewWatcher() (*Watcher, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir socket netlink: %v", err)
+	}
+
+	// O grupo 1 recebe os eventos diretamente do kernel
+	addr := &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK, Groups: 1}
+	if err := syscall.Bind(fd, addr); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("erro ao associar socket netlink: %v", err)
+	}
+
+	// Em modo não bloqueante o runtime consegue interromper a leitura no Close
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("erro ao configurar socket netlink: %v", err)
+	}
+
+	w := &Watcher{
+		file:    os.NewFile(uintptr(fd), "netlink-uevent"),
+		events:  make(chan Event, 32),
+		devices: make(map[string]model.USBDevice),
+	}
+	w.scanExisting()
+
+	go w.readLoop()
+
+	return w, nil
+}
+
+// Events retorna o canal de eventos, fechado quando o watcher é encerrado
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// OnHubAttached registra uma função chamada com o diretório do sysfs de
+// cada hub conectado. Os hubs não geram eventos, mas precisam ser
+// autorizados quando o kernel não autoriza os dispositivos novos.
+func (w *Watcher) OnHubAttached(handler func(dir string)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onHub = handler
+}
+
+// Close encerra o monitoramento
+func (w *Watcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		err = w.file.Close()
+	})
+	return err
+}
+
+// scanExisting registra os dispositivos já conectados para que sua
+// remoção possa ser reportada com os dados completos
+func (w *Watcher) scanExisting() {
+	entries, err := os.ReadDir(SysfsDevicesPath)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		// Interfaces ("1-2:1.0") e root hubs ("usb1") não são dispositivos
+		if strings.Contains(entry.Name(), ":") || strings.HasPrefix(entry.Name(), "usb") {
+			continue
+		}
+
+		dir, err := filepath.EvalSymlinks(filepath.Join(SysfsDevicesPath, entry.Name()))
+		if err != nil || isHub(dir) {
+			continue
+		}
+		if dev, err := ReadDevice(dir); err == nil {
+			w.devices[strings.TrimPrefix(dir, "/sys")] = dev
+		}
+	}
+}
+
+func (w *Watcher) readLoop() {
+	defer close(w.events)
+
+	buf := make([]byte, ueventBufferSize)
+	for {
+		n, err := w.file.Read(buf)
+		if errors.Is(err, syscall.ENOBUFS) {
+			// Eventos perdidos por estouro do buffer do socket
+			continue
+		}
+		if err != nil {
+			return
+		}
+
+		if event, ok := w.handleUevent(parseUevent(buf[:n])); ok {
+			w.events <- event
+		}
+	}
+}
+
+// handleUevent converte um uevent em Event quando se trata da conexão ou
+// remoção de um dispositivo USB (interfaces e hubs são ignorados)
+func (w *Watcher) handleUevent(env map[string]string) (Event, bool) {
+	if env["SUBSYSTEM"] != "usb" || env["DEVTYPE"] != "usb_device" {
+		return Event{}, false
+	}
+
+	devPath := env["DEVPATH"]
+	event := Event{
+		Time: time.Now(),
+		Port: filepath.Base(devPath),
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch env["ACTION"] {
+	case "add":
+		dir := filepath.Join("/sys", devPath)
+		if isHub(dir) {
+			if w.onHub != nil {
+				w.onHub(dir)
+			}
+			return Event{}, false
+		}
+		dev, err := ReadDevice(dir)
+		if err != nil {
+			dev = deviceFromProduct(env["PRODUCT"], event.Port)
+		}
+		w.devices[devPath] = dev
+		event.Type = DeviceAttached
+		event.Device = dev
+	case "remove":
+		dev, ok := w.devices[devPath]
+		if !ok {
+			// Hubs não são registrados; sem PRODUCT não há o que reportar
+			if env["PRODUCT"] == "" || strings.HasPrefix(env["TYPE"], "9/") {
+				return Event{}, false
+			}
+			dev = deviceFromProduct(env["PRODUCT"], event.Port)
+		}
+		delete(w.devices, devPath)
+		event.Type = DeviceDetached
+		event.Device = dev
+	default:
+		return Event{}, false
+	}
+
+	return event, true
+}
+
+// parseUevent separa as variáveis de ambiente de uma mensagem de uevent
+// ("add@/devices/...\0ACTION=add\0DEVPATH=...\0")
+func parseUevent(msg []byte) map[string]string {
+	env := make(map[string]string)
+	for _, field := range bytes.Split(msg, []byte{0}) {
+		key, value, ok := strings.Cut(string(field), "=")
+		if ok {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// deviceFromProduct monta o dispositivo a partir da variável PRODUCT do
+// uevent ("46d/c52b/1201"), usada quando o sysfs não está disponível
+func deviceFromProduct(product, port string) model.USBDevice {
+	parts := strings.Split(product, "/")
+	dev := model.USBDevice{Port: port}
+	if len(parts) >= 2 {
+		dev.VendorID = padID(parts[0])
+		dev.ProductID = padID(parts[1])
+	}
+	dev.Name = fmt.Sprintf("USB Device %s:%s", dev.VendorID, dev.ProductID)
+	return dev
+}
+
+// padID completa o identificador hexadecimal com zeros à esquerda
+func padID(id string) string {
+	if len(id) >= 4 {
+		return id
+	}
+	return strings.Repeat("0", 4-len(id)) + id
+}
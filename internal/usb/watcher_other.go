@@ -0,0 +1,31 @@
+//go:build !linux
+
+package usb
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Watcher monitora a conexão de dispositivos USB (disponível apenas no Linux)
+type Watcher struct {
+	events chan Event
+}
+
+// NewWatcher retorna erro nas plataformas sem suporte a uevents
+func NewWatcher() (*Watcher, error) {
+	return nil, fmt.Errorf("monitoramento de USB não suportado em %s", runtime.GOOS)
+}
+
+// Events retorna o canal de eventos
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// OnHubAttached registra uma função chamada a cada hub conectado
+func (w *Watcher) OnHubAttached(handler func(dir string)) {}
+
+// Close encerra o monitoramento
+func (w *Watcher) Close() error {
+	return nil
+}
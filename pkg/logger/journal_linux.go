@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// journalSocket é o socket do protocolo nativo do systemd-journald
+const journalSocket = "/run/systemd/journal/socket"
+
+// journalHandler é um slog.Handler que envia as mensagens ao journal pelo
+// protocolo nativo, com cada campo estruturado como um campo do journal
+// (component → COMPONENT, usb_vendor → USB_VENDOR)
+type journalHandler struct {
+	conn   *net.UnixConn
+	level  slog.Leveler
+	fields fields
+}
+
+// newJournalHandler conecta ao journal; falha se o systemd-journald não
+// estiver em execução
+func newJournalHandler(level slog.Leveler) (*journalHandler, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journalSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("journal do systemd indisponível: %v", err)
+	}
+	return &journalHandler{conn: conn, level: level}, nil
+}
+
+func (h *journalHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *journalHandler) Handle(_ context.Context, record slog.Record) error {
+	var buf bytes.Buffer
+	writeJournalField(&buf, "MESSAGE", record.Message)
+	writeJournalField(&buf, "PRIORITY", strconv.Itoa(severity(record.Level)))
+	writeJournalField(&buf, "SYSLOG_IDENTIFIER", appName)
+	if function, file, line := source(record); file != "" {
+		writeJournalField(&buf, "CODE_FILE", file)
+		writeJournalField(&buf, "CODE_LINE", strconv.Itoa(line))
+		writeJournalField(&buf, "CODE_FUNC", function)
+	}
+	for _, a := range h.fields.collect(record) {
+		if name := journalFieldName(a.Key); name != "" {
+			writeJournalField(&buf, name, a.Value.String())
+		}
+	}
+
+	_, err := h.conn.Write(buf.Bytes())
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		return h.sendLarge(buf.Bytes())
+	}
+	return err
+}
+
+// sendLarge envia mensagens maiores que um datagrama: o conteúdo vai em um
+// memfd selado, cujo descritor é passado ao journald
+func (h *journalHandler) sendLarge(data []byte) error {
+	fd, err := unix.MemfdCreate("journal-message", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return fmt.Errorf("erro ao criar memfd para o journal: %v", err)
+	}
+	file := os.NewFile(uintptr(fd), "journal-message")
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+	if _, err := unix.FcntlInt(uintptr(fd), unix.F_ADD_SEALS, unix.F_SEAL_SHRINK|unix.F_SEAL_GROW|unix.F_SEAL_WRITE|unix.F_SEAL_SEAL); err != nil {
+		return fmt.Errorf("erro ao selar memfd para o journal: %v", err)
+	}
+
+	_, _, err = h.conn.WriteMsgUnix(nil, unix.UnixRights(fd), nil)
+	return err
+}
+
+func (h *journalHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.fields = h.fields.withAttrs(attrs)
+	return &clone
+}
+
+func (h *journalHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.fields = h.fields.withGroup(name)
+	return &clone
+}
+
+func (h *journalHandler) close() error {
+	return h.conn.Close()
+}
+
+// writeJournalField grava NOME=valor, ou o formato binário (nome, tamanho
+// em 64 bits little-endian e valor) quando o valor tem quebras de linha
+func writeJournalField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journalFieldName converte a chave no nome de campo aceito pelo journal:
+// maiúsculas, dígitos e "_", sem começar por "_" (reservado ao journald)
+// nem por dígito
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, key)
+	name = strings.TrimLeft(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "F_" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJournalFieldName(t *testing.T) {
+	tests := map[string]string{
+		"component":             "COMPONENT",
+		"usb.vendor-id":         "USB_VENDOR_ID",
+		"_private":              "PRIVATE",
+		"2fa":                   "F_2FA",
+		"ação":                  "A__O",
+		"___":                   "",
+		strings.Repeat("a", 70): strings.Repeat("A", 64),
+	}
+	for key, want := range tests {
+		if got := journalFieldName(key); got != want {
+			t.Errorf("journalFieldName(%q) = %q, quer %q", key, got, want)
+		}
+	}
+}
+
+// parseJournalFields interpreta um datagrama do protocolo nativo do journal
+func parseJournalFields(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	fields := make(map[string]string)
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			t.Fatalf("campo sem quebra de linha: %q", data)
+		}
+		line := data[:end]
+		if name, value, ok := bytes.Cut(line, []byte("=")); ok {
+			fields[string(name)] = string(value)
+			data = data[end+1:]
+			continue
+		}
+
+		// Formato binário: nome, tamanho little-endian e valor
+		rest := data[end+1:]
+		if len(rest) < 8 {
+			t.Fatalf("campo binário %s truncado", line)
+		}
+		size := binary.LittleEndian.Uint64(rest)
+		if uint64(len(rest)) < 8+size+1 || rest[8+size] != '\n' {
+			t.Fatalf("campo binário %s com tamanho %d inválido", line, size)
+		}
+		fields[string(line)] = string(rest[8 : 8+size])
+		data = rest[8+size+1:]
+	}
+	return fields
+}
+
+func TestWriteJournalField(t *testing.T) {
+	var buf bytes.Buffer
+	writeJournalField(&buf, "MESSAGE", "uma linha")
+	writeJournalField(&buf, "DETAILS", "linha 1\nlinha 2=x\n")
+	writeJournalField(&buf, "EMPTY", "")
+
+	want := map[string]string{"MESSAGE": "uma linha", "DETAILS": "linha 1\nlinha 2=x\n", "EMPTY": ""}
+	got := parseJournalFields(t, buf.Bytes())
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("%s = %q, quer %q", name, got[name], value)
+		}
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("MESSAGE=uma linha\nDETAILS\n")) {
+		t.Errorf("formato = %q", buf.Bytes())
+	}
+}
+
+func TestJournalHandler(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "journal.sock")
+	server, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &journalHandler{conn: conn, level: slog.LevelInfo}
+	defer h.close()
+
+	slog.New(h).With("component", "usb").Warn("Dispositivo bloqueado\nsegunda linha", "usb_vendor", "046d")
+
+	buf := make([]byte, 64*1024)
+	server.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := server.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := parseJournalFields(t, buf[:n])
+	want := map[string]string{
+		"MESSAGE":           "Dispositivo bloqueado\nsegunda linha",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "falcon-agent",
+		"COMPONENT":         "usb",
+		"USB_VENDOR":        "046d",
+	}
+	for name, value := range want {
+		if fields[name] != value {
+			t.Errorf("%s = %q, quer %q", name, fields[name], value)
+		}
+	}
+	if !strings.HasSuffix(fields["CODE_FILE"], "journal_linux_test.go") {
+		t.Errorf("CODE_FILE = %q", fields["CODE_FILE"])
+	}
+}
@@ -0,0 +1,28 @@
+//go:build !linux
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+)
+
+// journalHandler existe apenas no Linux
+type journalHandler struct{}
+
+// newJournalHandler retorna erro nas plataformas sem systemd
+func newJournalHandler(level slog.Leveler) (*journalHandler, error) {
+	return nil, fmt.Errorf("journal do systemd não suportado em %s", runtime.GOOS)
+}
+
+func (h *journalHandler) Enabled(context.Context, slog.Level) bool { return false }
+
+func (h *journalHandler) Handle(context.Context, slog.Record) error { return nil }
+
+func (h *journalHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *journalHandler) WithGroup(string) slog.Handler { return h }
+
+func (h *journalHandler) close() error { return nil }
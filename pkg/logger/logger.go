@@ -1,62 +1,303 @@
 package logger
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
-// Logger é uma interface para logging
+// Logger é uma interface para logging. As mensagens usam o formato de
+// fmt.Printf; campos estruturados são acrescentados com With.
 type Logger interface {
+	Debug(format string, v ...interface{})
 	Info(format string, v ...interface{})
+	Warn(format string, v ...interface{})
 	Error(format string, v ...interface{})
-	Debug(format string, v ...interface{})
+	// With retorna um logger que inclui os pares chave/valor em todas as
+	// mensagens (ex.: With("component", "api"))
+	With(args ...any) Logger
+}
+
+// Formatos de saída
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// FileName é o nome do arquivo de log no diretório configurado
+const FileName = "falcon-agent.log"
+
+// Options configura o logger
+type Options struct {
+	// Dir é o diretório do arquivo de log
+	Dir string
+	// Level é o nível mínimo: debug, info, warn ou error
+	Level string
+	// Format é o formato das linhas: text (padrão) ou json
+	Format string
+	// Rotation define a rotação e a retenção dos arquivos; o valor zero
+	// usa DefaultRotateOptions
+	Rotation RotateOptions
+	// Sinks são os destinos das mensagens: file, syslog e journald
+	// (padrão apenas file)
+	Sinks []string
+	// Syslog configura o destino syslog
+	Syslog SyslogOptions
+	// Stdout também escreve as mensagens na saída padrão. É ignorado com o
+	// destino journald quando a saída padrão já vai para o journal, para
+	// não duplicar as mensagens.
+	Stdout bool
 }
 
-// FileLogger implementa a interface Logger
+// FileLogger implementa a interface Logger sobre log/slog, gravando em um
+// arquivo rotacionado por tamanho e por dia e, opcionalmente, no syslog e
+// no journal do systemd
 type FileLogger struct {
-	infoLogger  *log.Logger
-	errorLogger *log.Logger
-	debugLogger *log.Logger
+	slog  *slog.Logger
+	level *slog.LevelVar
+	// file é nil quando o destino file não está configurado
+	file    *RotatingFile
+	closers []func() error
 }
 
 // New cria um novo logger
-func New(logPath string) (*FileLogger, error) {
-	// Cria o diretório de logs se não existir
-	if err := os.MkdirAll(logPath, 0755); err != nil {
-		return nil, fmt.Errorf("erro ao criar diretório de logs: %v", err)
+func New(opts Options) (*FileLogger, error) {
+	level := new(slog.LevelVar)
+	if opts.Level != "" {
+		l, err := ParseLevel(opts.Level)
+		if err != nil {
+			return nil, err
+		}
+		level.Set(l)
+	}
+	if opts.Rotation == (RotateOptions{}) {
+		opts.Rotation = DefaultRotateOptions()
+	}
+	if len(opts.Sinks) == 0 {
+		opts.Sinks = []string{SinkFile}
+	}
+
+	l := &FileLogger{level: level}
+	var handlers multiHandler
+	var writers []io.Writer
+	for _, sink := range opts.Sinks {
+		switch sink {
+		case SinkFile:
+			file, err := OpenRotatingFile(filepath.Join(opts.Dir, FileName), opts.Rotation)
+			if err != nil {
+				l.Close()
+				return nil, err
+			}
+			l.file = file
+			l.closers = append(l.closers, file.Close)
+			writers = append(writers, file)
+		case SinkSyslog:
+			handler, err := newSyslogHandler(opts.Syslog, level)
+			if err != nil {
+				l.Close()
+				return nil, err
+			}
+			l.closers = append(l.closers, handler.w.close)
+			handlers = append(handlers, handler)
+		case SinkJournal:
+			handler, err := newJournalHandler(level)
+			if err != nil {
+				l.Close()
+				return nil, err
+			}
+			l.closers = append(l.closers, handler.close)
+			handlers = append(handlers, handler)
+			// Sob o systemd a saída padrão já chega ao journal
+			if os.Getenv("JOURNAL_STREAM") != "" {
+				opts.Stdout = false
+			}
+		default:
+			l.Close()
+			return nil, fmt.Errorf("destino de log inválido: %s", sink)
+		}
+	}
+	if opts.Stdout {
+		writers = append([]io.Writer{os.Stdout}, writers...)
 	}
 
-	// Abre o arquivo de log
-	logFile := filepath.Join(logPath, "falcon-agent.log")
-	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if len(writers) > 0 {
+		handler, err := newHandler(io.MultiWriter(writers...), opts.Format, level)
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+		handlers = append(multiHandler{handler}, handlers...)
+	}
+
+	if len(handlers) == 1 {
+		l.slog = slog.New(handlers[0])
+	} else {
+		l.slog = slog.New(handlers)
+	}
+	return l, nil
+}
+
+// newHandler cria o handler do slog no formato pedido, com a origem da
+// mensagem reduzida a arquivo:linha
+func newHandler(w io.Writer, format string, level slog.Leveler) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{
+		AddSource: true,
+		Level:     level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.SourceKey && len(groups) == 0 {
+				if src, ok := a.Value.Any().(*slog.Source); ok {
+					return slog.String(slog.SourceKey, fmt.Sprintf("%s:%d", filepath.Base(src.File), src.Line))
+				}
+			}
+			return a
+		},
+	}
+
+	switch format {
+	case "", FormatText:
+		return slog.NewTextHandler(w, opts), nil
+	case FormatJSON:
+		return slog.NewJSONHandler(w, opts), nil
+	}
+	return nil, fmt.Errorf("formato de log inválido: %s", format)
+}
+
+// ParseLevel converte o nome do nível ("debug", "info", "warn" ou "error")
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("nível de log inválido: %s", name)
+}
+
+// SetLevel define o nível mínimo registrado
+func (l *FileLogger) SetLevel(name string) error {
+	level, err := ParseLevel(name)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao abrir arquivo de log: %v", err)
+		return err
 	}
+	l.level.Set(level)
+	return nil
+}
 
-	// Cria um multi-writer para escrever tanto no arquivo quanto no stdout
-	multiWriter := io.MultiWriter(os.Stdout, file)
+// SetRotation troca os limites de rotação e retenção do arquivo de log
+func (l *FileLogger) SetRotation(opts RotateOptions) {
+	if l.file != nil {
+		l.file.SetOptions(opts)
+	}
+}
 
-	return &FileLogger{
-		infoLogger:  log.New(multiWriter, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile),
-		errorLogger: log.New(multiWriter, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile),
-		debugLogger: log.New(multiWriter, "DEBUG: ", log.Ldate|log.Ltime|log.Lshortfile),
-	}, nil
+// Slog retorna o *slog.Logger usado internamente
+func (l *FileLogger) Slog() *slog.Logger {
+	return l.slog
+}
+
+// Close fecha o arquivo de log e as conexões com o syslog e o journal
+func (l *FileLogger) Close() error {
+	var errs []error
+	for _, closer := range l.closers {
+		if err := closer(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Debug registra mensagens de debug
+func (l *FileLogger) Debug(format string, v ...interface{}) {
+	logf(l.slog, slog.LevelDebug, format, v...)
 }
 
 // Info registra mensagens de informação
 func (l *FileLogger) Info(format string, v ...interface{}) {
-	l.infoLogger.Printf(format, v...)
+	logf(l.slog, slog.LevelInfo, format, v...)
+}
+
+// Warn registra avisos
+func (l *FileLogger) Warn(format string, v ...interface{}) {
+	logf(l.slog, slog.LevelWarn, format, v...)
 }
 
 // Error registra mensagens de erro
 func (l *FileLogger) Error(format string, v ...interface{}) {
-	l.errorLogger.Printf(format, v...)
+	logf(l.slog, slog.LevelError, format, v...)
 }
 
-// Debug registra mensagens de debug
-func (l *FileLogger) Debug(format string, v ...interface{}) {
-	l.debugLogger.Printf(format, v...)
+// With retorna um logger com os campos acrescentados
+func (l *FileLogger) With(args ...any) Logger {
+	return &slogLogger{slog: l.slog.With(args...)}
+}
+
+// slogLogger é um Logger sobre um *slog.Logger qualquer; é o tipo
+// retornado por With e por Default
+type slogLogger struct {
+	slog *slog.Logger
+}
+
+func (l *slogLogger) Debug(format string, v ...interface{}) {
+	logf(l.slog, slog.LevelDebug, format, v...)
+}
+
+func (l *slogLogger) Info(format string, v ...interface{}) {
+	logf(l.slog, slog.LevelInfo, format, v...)
+}
+
+func (l *slogLogger) Warn(format string, v ...interface{}) {
+	logf(l.slog, slog.LevelWarn, format, v...)
+}
+
+func (l *slogLogger) Error(format string, v ...interface{}) {
+	logf(l.slog, slog.LevelError, format, v...)
+}
+
+func (l *slogLogger) With(args ...any) Logger {
+	return &slogLogger{slog: l.slog.With(args...)}
+}
+
+// logf formata a mensagem e a registra com a origem de quem chamou o
+// método do Logger, e não a deste pacote
+func logf(logger *slog.Logger, level slog.Level, format string, v ...interface{}) {
+	ctx := context.Background()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // runtime.Callers, logf e o método do Logger
+	record := slog.NewRecord(time.Now(), level, fmt.Sprintf(format, v...), pcs[0])
+	logger.Handler().Handle(ctx, record)
+}
+
+var defaultLogger atomic.Pointer[slogLogger]
+
+// SetDefault define o logger usado por Default e pelos pacotes log e
+// log/slog da biblioteca padrão
+func SetDefault(l *FileLogger) {
+	defaultLogger.Store(&slogLogger{slog: l.slog})
+	slog.SetDefault(l.slog)
+}
+
+// Default retorna o logger do processo, para os pacotes que não recebem um
+// Logger na construção. Antes de SetDefault, as mensagens vão para a saída
+// de erro.
+func Default() Logger {
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	return &slogLogger{slog: slog.Default()}
 }
@@ -0,0 +1,409 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// backupLayout é o formato da data e hora acrescentada aos arquivos rotacionados
+	backupLayout = "2006-01-02_15-04-05"
+	dayLayout    = "2006-01-02"
+)
+
+// RotateOptions define quando o arquivo de log é rotacionado e quantos
+// arquivos antigos são mantidos
+type RotateOptions struct {
+	// MaxSize é o tamanho, em bytes, a partir do qual o arquivo é rotacionado
+	MaxSize int64
+	// Daily também rotaciona o arquivo na virada do dia
+	Daily bool
+	// MaxBackups é o número máximo de arquivos antigos mantidos (zero mantém todos)
+	MaxBackups int
+	// MaxAge apaga os arquivos antigos mais velhos que a duração (zero mantém todos)
+	MaxAge time.Duration
+	// Compress comprime os arquivos rotacionados com gzip
+	Compress bool
+}
+
+// DefaultRotateOptions retorna a rotação padrão: arquivos de até 10 MiB,
+// um por dia, comprimidos, mantidos por 30 dias e no máximo 10
+func DefaultRotateOptions() RotateOptions {
+	return RotateOptions{
+		MaxSize:    10 << 20,
+		Daily:      true,
+		MaxBackups: 10,
+		MaxAge:     30 * 24 * time.Hour,
+		Compress:   true,
+	}
+}
+
+// RotatingFile é um io.Writer que grava em um arquivo e o rotaciona por
+// tamanho ou na virada do dia, renomeando-o com a data e hora. Os arquivos
+// rotacionados são comprimidos e os excedentes apagados em segundo plano,
+// sem bloquear a escrita. É seguro para uso por várias goroutines.
+type RotatingFile struct {
+	mu       sync.Mutex
+	filename string
+	opts     RotateOptions
+	file     *os.File
+	size     int64
+	// day é a data (yyyy-mm-dd) das linhas gravadas no arquivo atual
+	day    string
+	closed bool
+
+	// cleanup acorda a goroutine de manutenção dos arquivos antigos
+	cleanup chan struct{}
+	done    chan struct{}
+}
+
+// OpenRotatingFile abre (ou cria) o arquivo para escrita no final. Arquivos
+// antigos deixados por execuções anteriores são comprimidos e apagados
+// conforme opts.
+func OpenRotatingFile(filename string, opts RotateOptions) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de logs: %v", err)
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultRotateOptions().MaxSize
+	}
+
+	r := &RotatingFile{
+		filename: filename,
+		opts:     opts,
+		cleanup:  make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	go r.maintain()
+	r.cleanup <- struct{}{}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de log: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+
+	// Um arquivo que já tem linhas pertence ao dia da última escrita, para
+	// que o log de ontem seja rotacionado mesmo após um reinício
+	r.day = time.Now().Format(dayLayout)
+	if r.size > 0 {
+		r.day = info.ModTime().Format(dayLayout)
+	}
+	return nil
+}
+
+// SetOptions troca os limites de rotação; os novos limites valem a partir
+// da próxima escrita e os arquivos antigos são revistos imediatamente
+func (r *RotatingFile) SetOptions(opts RotateOptions) {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultRotateOptions().MaxSize
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.opts = opts
+	if !r.closed {
+		r.requestCleanup()
+	}
+}
+
+// Write grava p, rotacionando o arquivo antes se necessário. Cada chamada
+// é uma linha completa de log, que nunca é dividida entre dois arquivos.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	if r.file == nil {
+		// A reabertura falhou na última rotação
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.size > 0 && r.shouldRotate(len(p)) {
+		if err := r.rotate(); err != nil {
+			// Sem rotação o log continua no arquivo atual
+			fmt.Fprintf(os.Stderr, "Erro ao rotacionar log: %v\n", err)
+			if r.file == nil {
+				return 0, err
+			}
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *RotatingFile) shouldRotate(next int) bool {
+	if r.size+int64(next) > r.opts.MaxSize {
+		return true
+	}
+	return r.opts.Daily && time.Now().Format(dayLayout) != r.day
+}
+
+// rotate renomeia o arquivo atual com a data e hora e abre um novo. A
+// compressão e a limpeza ficam para a goroutine de manutenção. Deve ser
+// chamada com mu travado.
+func (r *RotatingFile) rotate() error {
+	err := r.file.Close()
+	r.file = nil
+	if err != nil {
+		return err
+	}
+
+	renameErr := os.Rename(r.filename, r.backupName(time.Now()))
+
+	if err := r.open(); err != nil {
+		return err
+	}
+	if renameErr == nil {
+		r.requestCleanup()
+	}
+	return renameErr
+}
+
+// backupName retorna um nome livre para o arquivo rotacionado; rotações no
+// mesmo segundo recebem um sufixo numérico
+func (r *RotatingFile) backupName(t time.Time) string {
+	base := fmt.Sprintf("%s.%s", r.filename, t.Format(backupLayout))
+	name := base
+	for i := 1; exists(name) || exists(name+".gz"); i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	return name
+}
+
+func exists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
+
+func (r *RotatingFile) requestCleanup() {
+	select {
+	case r.cleanup <- struct{}{}:
+	default:
+	}
+}
+
+// maintain comprime e apaga os arquivos rotacionados, um pedido por vez,
+// até o canal cleanup ser fechado
+func (r *RotatingFile) maintain() {
+	defer close(r.done)
+	for range r.cleanup {
+		r.mu.Lock()
+		opts := r.opts
+		r.mu.Unlock()
+
+		if err := r.cleanBackups(opts, time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "Erro ao limpar logs antigos: %v\n", err)
+		}
+	}
+}
+
+// backup é um arquivo de log rotacionado
+type backup struct {
+	path    string
+	modTime time.Time
+	// rotated e seq vêm do nome e ordenam os arquivos na ordem da rotação
+	rotated time.Time
+	seq     int
+}
+
+// cleanBackups comprime os arquivos rotacionados ainda sem compressão e
+// apaga os que excedem MaxBackups ou são mais velhos que MaxAge
+func (r *RotatingFile) cleanBackups(opts RotateOptions, now time.Time) error {
+	backups, err := r.listBackups()
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	var kept []backup
+	for i, b := range backups {
+		expired := opts.MaxAge > 0 && now.Sub(b.modTime) > opts.MaxAge
+		excess := opts.MaxBackups > 0 && i >= opts.MaxBackups
+		if expired || excess {
+			if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err.Error())
+			}
+			continue
+		}
+		kept = append(kept, b)
+	}
+
+	if opts.Compress {
+		for _, b := range kept {
+			if strings.HasSuffix(b.path, ".gz") {
+				continue
+			}
+			if err := compressFile(b.path); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// listBackups retorna os arquivos rotacionados, do mais novo para o mais
+// antigo. Restos de compressões interrompidas são apagados.
+func (r *RotatingFile) listBackups() ([]backup, error) {
+	dir := filepath.Dir(r.filename)
+	prefix := filepath.Base(r.filename) + "."
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar diretório de logs: %v", err)
+	}
+
+	var backups []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if strings.HasSuffix(name, ".tmp") {
+			os.Remove(path)
+			continue
+		}
+		rotated, seq, ok := parseBackupSuffix(strings.TrimPrefix(name, prefix))
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: path, modTime: info.ModTime(), rotated: rotated, seq: seq})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].rotated.Equal(backups[j].rotated) {
+			return backups[i].rotated.After(backups[j].rotated)
+		}
+		return backups[i].seq > backups[j].seq
+	})
+	return backups, nil
+}
+
+// parseBackupSuffix interpreta o sufixo de um arquivo rotacionado
+// ("2024-05-10_14-02-11-1.gz" → data e hora da rotação e sequência 1)
+func parseBackupSuffix(suffix string) (time.Time, int, bool) {
+	suffix = strings.TrimSuffix(suffix, ".gz")
+	if len(suffix) < len(backupLayout) {
+		return time.Time{}, 0, false
+	}
+
+	rotated, err := time.ParseInLocation(backupLayout, suffix[:len(backupLayout)], time.Local)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+
+	seq := 0
+	if rest := suffix[len(backupLayout):]; rest != "" {
+		n, err := strconv.Atoi(strings.TrimPrefix(rest, "-"))
+		if err != nil || !strings.HasPrefix(rest, "-") {
+			return time.Time{}, 0, false
+		}
+		seq = n
+	}
+	return rotated, seq, true
+}
+
+// compressFile grava path.gz e apaga path. O arquivo comprimido é escrito
+// em um temporário e renomeado, para que uma interrupção não deixe um .gz
+// pela metade.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("erro ao comprimir %s: %v", path, err)
+	}
+
+	gz := gzip.NewWriter(dst)
+	gz.Name = filepath.Base(path)
+	gz.ModTime = info.ModTime()
+	_, err = io.Copy(gz, src)
+	if err == nil {
+		err = gz.Close()
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("erro ao comprimir %s: %v", path, err)
+	}
+
+	// Mantém a data original para que MaxAge considere a última escrita
+	os.Chtimes(tmp, info.ModTime(), info.ModTime())
+	if err := os.Rename(tmp, path+".gz"); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("erro ao comprimir %s: %v", path, err)
+	}
+	return os.Remove(path)
+}
+
+// Close fecha o arquivo e espera a compressão em andamento terminar
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+	r.closed = true
+	close(r.cleanup)
+	r.mu.Unlock()
+
+	<-r.done
+	return err
+}
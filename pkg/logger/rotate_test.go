@@ -0,0 +1,285 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// readLog lê o arquivo de log, descomprimindo os rotacionados com gzip
+func readLog(t *testing.T, path string) string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// backupFiles lista os arquivos rotacionados, do mais novo para o mais antigo
+func backupFiles(t *testing.T, filename string) []string {
+	t.Helper()
+	r := &RotatingFile{filename: filename}
+	backups, err := r.listBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make([]string, len(backups))
+	for i, b := range backups {
+		paths[i] = b.path
+	}
+	return paths
+}
+
+func writeLines(t *testing.T, w io.Writer, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "agent.log")
+	r, err := OpenRotatingFile(filename, RotateOptions{MaxSize: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cada linha tem 10 bytes: a terceira não cabe no arquivo
+	writeLines(t, r, "linha 001", "linha 002", "linha 003")
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readLog(t, filename); got != "linha 003\n" {
+		t.Errorf("arquivo atual = %q", got)
+	}
+	backups := backupFiles(t, filename)
+	if len(backups) != 1 {
+		t.Fatalf("arquivos rotacionados = %v, quer 1", backups)
+	}
+	if got := readLog(t, backups[0]); got != "linha 001\nlinha 002\n" {
+		t.Errorf("arquivo rotacionado = %q", got)
+	}
+}
+
+func TestRotatingFileRotatesDaily(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "agent.log")
+	if err := os.WriteFile(filename, []byte("ontem\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	yesterday := time.Now().Add(-24 * time.Hour)
+	if err := os.Chtimes(filename, yesterday, yesterday); err != nil {
+		t.Fatal(err)
+	}
+
+	// O arquivo deixado por uma execução anterior pertence ao dia da
+	// última escrita e é rotacionado na primeira linha de hoje
+	r, err := OpenRotatingFile(filename, RotateOptions{MaxSize: 1 << 20, Daily: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, r, "hoje")
+	writeLines(t, r, "de novo hoje")
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readLog(t, filename); got != "hoje\nde novo hoje\n" {
+		t.Errorf("arquivo atual = %q", got)
+	}
+	backups := backupFiles(t, filename)
+	if len(backups) != 1 || readLog(t, backups[0]) != "ontem\n" {
+		t.Errorf("arquivos rotacionados = %v, quer só o de ontem", backups)
+	}
+}
+
+func TestRotatingFileCompresses(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "agent.log")
+	// Resto de uma compressão interrompida
+	stale := filename + ".2024-05-10_14-02-11.gz.tmp"
+	if err := os.WriteFile(stale, []byte("pela metade"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenRotatingFile(filename, RotateOptions{MaxSize: 10, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, r, "linha 001", "linha 002")
+	// Close espera a compressão em andamento
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups := backupFiles(t, filename)
+	if len(backups) != 1 || !strings.HasSuffix(backups[0], ".gz") {
+		t.Fatalf("arquivos rotacionados = %v, quer um .gz", backups)
+	}
+	if got := readLog(t, backups[0]); got != "linha 001\n" {
+		t.Errorf("conteúdo comprimido = %q", got)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("arquivo temporário %s não foi removido", entry.Name())
+		}
+	}
+	if _, err := os.Stat(strings.TrimSuffix(backups[0], ".gz")); !os.IsNotExist(err) {
+		t.Errorf("arquivo sem compressão mantido: %v", err)
+	}
+}
+
+func TestRotatingFilePrunesBackups(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "agent.log")
+	now := time.Now()
+
+	// Um arquivo rotacionado por dia, do mais novo (0) ao mais antigo (4)
+	var names []string
+	for day := 0; day < 5; day++ {
+		modTime := now.Add(-time.Duration(day) * 24 * time.Hour)
+		name := filename + "." + modTime.Format(backupLayout)
+		if day%2 == 1 {
+			name += ".gz"
+		}
+		if err := os.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(name, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+
+	tests := []struct {
+		name string
+		opts RotateOptions
+		want []string
+	}{
+		{"sem limites", RotateOptions{}, names},
+		{"MaxAge", RotateOptions{MaxAge: 3*24*time.Hour + time.Hour}, names[:4]},
+		{"MaxBackups", RotateOptions{MaxBackups: 2}, names[:2]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &RotatingFile{filename: filename}
+			if err := r.cleanBackups(tt.opts, now); err != nil {
+				t.Fatal(err)
+			}
+			if got := backupFiles(t, filename); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("arquivos mantidos = %v, quer %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBackupSuffix(t *testing.T) {
+	tests := []struct {
+		suffix string
+		seq    int
+		ok     bool
+	}{
+		{"2024-05-10_14-02-11", 0, true},
+		{"2024-05-10_14-02-11.gz", 0, true},
+		{"2024-05-10_14-02-11-2", 2, true},
+		{"2024-05-10_14-02-11-12.gz", 12, true},
+		{"2024-05-10", 0, false},
+		{"2024-05-10_14-02-11x1", 0, false},
+		{"2024-05-10_14-02-11-a", 0, false},
+		{"old", 0, false},
+	}
+	for _, tt := range tests {
+		rotated, seq, ok := parseBackupSuffix(tt.suffix)
+		if ok != tt.ok || seq != tt.seq {
+			t.Errorf("parseBackupSuffix(%q) = %d, %v; quer %d, %v", tt.suffix, seq, ok, tt.seq, tt.ok)
+		}
+		if ok && rotated.Format(backupLayout) != "2024-05-10_14-02-11" {
+			t.Errorf("parseBackupSuffix(%q) = %v", tt.suffix, rotated)
+		}
+	}
+
+	// Rotações no mesmo segundo são ordenadas pela sequência, não pelo nome
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "agent.log")
+	for _, suffix := range []string{"2024-05-10_14-02-11", "2024-05-10_14-02-11-2.gz", "2024-05-10_14-02-11-10", "2024-05-09_23-59-59.gz"} {
+		if err := os.WriteFile(filename+"."+suffix, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"2024-05-10_14-02-11-10", "2024-05-10_14-02-11-2.gz", "2024-05-10_14-02-11", "2024-05-09_23-59-59.gz"}
+	for i, path := range backupFiles(t, filename) {
+		if got := strings.TrimPrefix(filepath.Base(path), "agent.log."); got != want[i] {
+			t.Errorf("arquivo %d = %s, quer %s", i, got, want[i])
+		}
+	}
+}
+
+func TestRotatingFileConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "agent.log")
+	r, err := OpenRotatingFile(filename, RotateOptions{MaxSize: 1024, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const writers, lines = 8, 200
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < lines; i++ {
+				fmt.Fprintf(r, "writer %d linha %03d\n", w, i)
+			}
+		}(w)
+	}
+	wg.Wait()
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nenhuma linha se perde ou é dividida entre dois arquivos
+	seen := make(map[string]bool)
+	for _, path := range append(backupFiles(t, filename), filename) {
+		scanner := bufio.NewScanner(strings.NewReader(readLog(t, path)))
+		for scanner.Scan() {
+			var w, i int
+			if _, err := fmt.Sscanf(scanner.Text(), "writer %d linha %d", &w, &i); err != nil {
+				t.Fatalf("%s: linha corrompida %q", path, scanner.Text())
+			}
+			seen[scanner.Text()] = true
+		}
+	}
+	if len(seen) != writers*lines {
+		t.Errorf("%d linhas encontradas, quer %d", len(seen), writers*lines)
+	}
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// Destinos das mensagens de log
+const (
+	SinkFile    = "file"
+	SinkSyslog  = "syslog"
+	SinkJournal = "journald"
+)
+
+// appName identifica o agente no syslog e no journal
+const appName = "falcon-agent"
+
+// multiHandler repassa cada mensagem a todos os destinos configurados. A
+// falha de um destino não impede a entrega aos demais.
+type multiHandler []slog.Handler
+
+func (h multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h multiHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, record.Level) {
+			if err := handler.Handle(ctx, record.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(multiHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (h multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make(multiHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return handlers
+}
+
+// fields acumula os campos estruturados de um handler que não usa os
+// handlers de texto do slog; grupos viram prefixos das chaves ("http.status")
+type fields struct {
+	attrs  []slog.Attr
+	prefix string
+}
+
+func (f fields) withAttrs(attrs []slog.Attr) fields {
+	all := make([]slog.Attr, len(f.attrs), len(f.attrs)+len(attrs))
+	copy(all, f.attrs)
+	for _, a := range attrs {
+		all = appendAttr(all, f.prefix, a)
+	}
+	return fields{attrs: all, prefix: f.prefix}
+}
+
+func (f fields) withGroup(name string) fields {
+	if name == "" {
+		return f
+	}
+	return fields{attrs: f.attrs, prefix: f.prefix + name + "."}
+}
+
+// collect retorna os campos do handler seguidos dos da mensagem
+func (f fields) collect(record slog.Record) []slog.Attr {
+	all := make([]slog.Attr, len(f.attrs), len(f.attrs)+record.NumAttrs())
+	copy(all, f.attrs)
+	record.Attrs(func(a slog.Attr) bool {
+		all = appendAttr(all, f.prefix, a)
+		return true
+	})
+	return all
+}
+
+// appendAttr acrescenta o campo, achatando grupos em chaves com prefixo
+func appendAttr(attrs []slog.Attr, prefix string, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return attrs
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			attrs = appendAttr(attrs, prefix, ga)
+		}
+		return attrs
+	}
+	return append(attrs, slog.Attr{Key: prefix + a.Key, Value: a.Value})
+}
+
+// severity converte o nível do slog na severidade do syslog (RFC 5424),
+// usada também como PRIORITY no journal
+func severity(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return 3
+	case level >= slog.LevelWarn:
+		return 4
+	case level >= slog.LevelInfo:
+		return 6
+	}
+	return 7
+}
+
+// source retorna a função, o arquivo e a linha de origem da mensagem
+func source(record slog.Record) (function, file string, line int) {
+	if record.PC == 0 {
+		return "", "", 0
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+	return frame.Function, frame.File, frame.Line
+}
+
+// Facilidades do syslog, na ordem dos códigos da RFC 5424
+var facilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// ParseFacility converte o nome da facilidade do syslog (ex.: "daemon",
+// "local0") no seu código
+func ParseFacility(name string) (int, error) {
+	for code, f := range facilities {
+		if strings.EqualFold(f, name) {
+			return code, nil
+		}
+	}
+	return 0, fmt.Errorf("facilidade de syslog inválida: %s", name)
+}
@@ -0,0 +1,318 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// syslogEnterpriseID identifica os parâmetros do agente no STRUCTURED-DATA
+	// (número reservado para exemplos e uso privado pela RFC 5612)
+	syslogEnterpriseID = "falcon@32473"
+	// syslogTimeout limita a espera por um servidor de syslog lento
+	syslogTimeout = 5 * time.Second
+	// syslogRetry é o intervalo entre tentativas de reconexão; enquanto o
+	// servidor está fora do ar as mensagens para o syslog são descartadas
+	syslogRetry = 30 * time.Second
+	// syslogQueueSize é o número de mensagens aguardando envio; acima dele
+	// as novas mensagens são descartadas
+	syslogQueueSize = 1024
+)
+
+// Sockets do syslog local, na ordem em que são tentados
+var localSyslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogOptions configura o envio ao syslog
+type SyslogOptions struct {
+	// Addr é o destino: vazio para o syslog local, "unix:///caminho",
+	// "udp://host:porta" ou "tcp://host:porta"
+	Addr string
+	// Facility é a facilidade das mensagens (padrão daemon)
+	Facility string
+}
+
+// ParseSyslogAddr separa o destino do syslog em rede e endereço. O destino
+// vazio indica o socket local, que é procurado na conexão.
+func ParseSyslogAddr(addr string) (network, address string, err error) {
+	if addr == "" {
+		return "", "", nil
+	}
+	network, address, ok := strings.Cut(addr, "://")
+	if !ok || address == "" {
+		return "", "", fmt.Errorf("destino de syslog inválido %q (use unix:///caminho, udp://host:porta ou tcp://host:porta)", addr)
+	}
+	switch network {
+	case "unix":
+	case "udp", "tcp":
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return "", "", fmt.Errorf("destino de syslog inválido %q: %v", addr, err)
+		}
+	default:
+		return "", "", fmt.Errorf("protocolo de syslog inválido %q (use unix, udp ou tcp)", network)
+	}
+	return network, address, nil
+}
+
+// syslogWriter envia as mensagens ao syslog em uma goroutine própria, de
+// modo que um servidor lento ou fora do ar não atrasa quem registra no
+// log. A conexão é aberta no primeiro envio e refeita quando cai.
+type syslogWriter struct {
+	network string
+	address string
+
+	// mu protege closed, para que nada seja enviado à fila já fechada
+	mu      sync.Mutex
+	closed  bool
+	queue   chan string
+	done    chan struct{}
+	dropped atomic.Int64
+
+	// Usados apenas pela goroutine de envio
+	conn net.Conn
+	// stream indica uma conexão orientada a fluxo, que precisa de
+	// delimitação entre as mensagens
+	stream  bool
+	retryAt time.Time
+}
+
+func newSyslogWriter(network, address string) *syslogWriter {
+	w := &syslogWriter{
+		network: network,
+		address: address,
+		queue:   make(chan string, syslogQueueSize),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// send coloca a mensagem na fila sem bloquear; com a fila cheia a
+// mensagem é descartada e contada
+func (w *syslogWriter) send(msg string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	select {
+	case w.queue <- msg:
+	default:
+		w.dropped.Add(1)
+	}
+}
+
+// run envia as mensagens da fila até ela ser fechada
+func (w *syslogWriter) run() {
+	defer close(w.done)
+	for msg := range w.queue {
+		if n := w.dropped.Swap(0); n > 0 {
+			fmt.Fprintf(os.Stderr, "Fila do syslog cheia: %d mensagens descartadas\n", n)
+		}
+		w.write(msg)
+	}
+	if w.conn != nil {
+		w.conn.Close()
+	}
+}
+
+func (w *syslogWriter) dial() error {
+	if w.network != "" {
+		conn, err := net.DialTimeout(w.network, w.address, syslogTimeout)
+		if err != nil {
+			return err
+		}
+		w.conn, w.stream = conn, w.network != "udp"
+		return nil
+	}
+
+	var err error
+	for _, path := range localSyslogSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			var conn net.Conn
+			conn, err = net.DialTimeout(network, path, syslogTimeout)
+			if err == nil {
+				w.conn, w.stream = conn, network == "unix"
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("syslog local indisponível: %v", err)
+}
+
+// write envia uma mensagem, tentando uma reconexão se a conexão caiu
+func (w *syslogWriter) write(msg string) error {
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			if time.Now().Before(w.retryAt) {
+				return nil
+			}
+			if err := w.dial(); err != nil {
+				w.fail(err)
+				return err
+			}
+		}
+
+		w.conn.SetWriteDeadline(time.Now().Add(syslogTimeout))
+		_, err := w.conn.Write(w.frame(msg))
+		if err == nil {
+			return nil
+		}
+		w.conn.Close()
+		w.conn = nil
+		if attempt == 1 {
+			w.fail(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// fail adia a próxima tentativa e avisa na saída de erro, já que o
+// próprio log pode estar indisponível
+func (w *syslogWriter) fail(err error) {
+	w.retryAt = time.Now().Add(syslogRetry)
+	fmt.Fprintf(os.Stderr, "Erro ao enviar log ao syslog, nova tentativa em %v: %v\n", syslogRetry, err)
+}
+
+// frame delimita a mensagem: contagem de octetos (RFC 6587) no TCP, quebra
+// de linha no socket local orientado a fluxo e nada em datagramas
+func (w *syslogWriter) frame(msg string) []byte {
+	switch {
+	case !w.stream:
+		return []byte(msg)
+	case w.network == "tcp":
+		return []byte(strconv.Itoa(len(msg)) + " " + msg)
+	}
+	return []byte(msg + "\n")
+}
+
+// close fecha a fila e aguarda o envio das mensagens pendentes, por no
+// máximo syslogTimeout
+func (w *syslogWriter) close() error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-time.After(syslogTimeout):
+		return fmt.Errorf("syslog: %d mensagens não enviadas", len(w.queue))
+	}
+}
+
+// syslogHandler é um slog.Handler que formata as mensagens segundo a
+// RFC 5424, com os campos estruturados no STRUCTURED-DATA
+type syslogHandler struct {
+	w        *syslogWriter
+	facility int
+	hostname string
+	level    slog.Leveler
+	fields   fields
+}
+
+// newSyslogHandler cria o handler; a conexão é aberta no envio da primeira
+// mensagem
+func newSyslogHandler(opts SyslogOptions, level slog.Leveler) (*syslogHandler, error) {
+	network, address, err := ParseSyslogAddr(opts.Addr)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Facility == "" {
+		opts.Facility = "daemon"
+	}
+	facility, err := ParseFacility(opts.Facility)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	return &syslogHandler{
+		w:        newSyslogWriter(network, address),
+		facility: facility,
+		hostname: hostname,
+		level:    level,
+	}, nil
+}
+
+func (h *syslogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *syslogHandler) Handle(_ context.Context, record slog.Record) error {
+	attrs := h.fields.collect(record)
+
+	msgID := "-"
+	var sd strings.Builder
+	for _, a := range attrs {
+		if a.Key == "component" {
+			msgID = syslogName(a.Value.String(), 32)
+		}
+		fmt.Fprintf(&sd, " %s=\"%s\"", syslogName(a.Key, 32), sdEscape(a.Value.String()))
+	}
+	if _, file, line := source(record); file != "" {
+		fmt.Fprintf(&sd, " source=\"%s:%d\"", sdEscape(filepath.Base(file)), line)
+	}
+
+	structured := "-"
+	if sd.Len() > 0 {
+		structured = "[" + syslogEnterpriseID + sd.String() + "]"
+	}
+
+	msg := fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s",
+		h.facility*8+severity(record.Level),
+		record.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogName(h.hostname, 255), appName, os.Getpid(), msgID, structured,
+		record.Message)
+	h.w.send(msg)
+	return nil
+}
+
+func (h *syslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.fields = h.fields.withAttrs(attrs)
+	return &clone
+}
+
+func (h *syslogHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.fields = h.fields.withGroup(name)
+	return &clone
+}
+
+// syslogName limita o texto aos caracteres aceitos nos campos de cabeçalho
+// e nos nomes de parâmetros da RFC 5424
+func syslogName(s string, max int) string {
+	name := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(name) > max {
+		name = name[:max]
+	}
+	if name == "" {
+		return "-"
+	}
+	return name
+}
+
+// sdEscape escapa os caracteres reservados nos valores do STRUCTURED-DATA
+var sdEscape = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace
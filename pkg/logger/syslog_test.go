@@ -0,0 +1,202 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readFrame lê uma mensagem delimitada pela contagem de octetos (RFC 6587)
+func readFrame(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	length, err := r.ReadString(' ')
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+	if err != nil {
+		t.Fatalf("contagem de octetos inválida %q", length)
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		t.Fatal(err)
+	}
+	return string(msg)
+}
+
+// listenSyslog aceita uma conexão TCP e entrega as mensagens recebidas
+func listenSyslog(t *testing.T) (string, func() string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	conns := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			conns <- conn
+		}
+	}()
+
+	var reader *bufio.Reader
+	next := func() string {
+		t.Helper()
+		if reader == nil {
+			select {
+			case conn := <-conns:
+				t.Cleanup(func() { conn.Close() })
+				conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+				reader = bufio.NewReader(conn)
+			case <-time.After(5 * time.Second):
+				t.Fatal("nenhuma conexão ao servidor de syslog")
+			}
+		}
+		return readFrame(t, reader)
+	}
+	return "tcp://" + ln.Addr().String(), next
+}
+
+func TestSyslogHandlerRFC5424(t *testing.T) {
+	addr, next := listenSyslog(t)
+	h, err := newSyslogHandler(SyslogOptions{Addr: addr, Facility: "local0"}, slog.LevelDebug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.w.close()
+	h.hostname = "estação 01"
+
+	log := slog.New(h).With("component", "usb")
+	log.Warn("Dispositivo bloqueado", "path", `C:\pasta "x" [y]`)
+	log.Info("linha 1\nlinha 2")
+
+	// local0 (16) * 8 + warning (4) = 132; fora do ASCII o hostname é trocado por "_"
+	header := regexp.MustCompile(`^<132>1 \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}(Z|[+-]\d{2}:\d{2}) ` +
+		`esta__o_01 falcon-agent ` + strconv.Itoa(os.Getpid()) + ` usb \[falcon@32473 (.*)\] Dispositivo bloqueado$`)
+	msg := next()
+	m := header.FindStringSubmatch(msg)
+	if m == nil {
+		t.Fatalf("mensagem fora do formato RFC 5424: %q", msg)
+	}
+	if sd := m[2]; !strings.HasPrefix(sd, `component="usb" path="C:\\pasta \"x\" [y\]" source="syslog_test.go:`) {
+		t.Errorf("STRUCTURED-DATA = %q", sd)
+	}
+
+	// A contagem de octetos delimita mensagens com quebra de linha
+	if msg := next(); !strings.HasPrefix(msg, "<134>1 ") || !strings.HasSuffix(msg, "] linha 1\nlinha 2") {
+		t.Errorf("segunda mensagem = %q", msg)
+	}
+}
+
+func TestSyslogName(t *testing.T) {
+	tests := map[string]string{
+		"usb":                   "usb",
+		"":                      "-",
+		"nome com espaço":       "nome_com_espa_o",
+		`a=b"c]d`:               "a_b_c_d",
+		strings.Repeat("x", 40): strings.Repeat("x", 32),
+	}
+	for in, want := range tests {
+		if got := syslogName(in, 32); got != want {
+			t.Errorf("syslogName(%q) = %q, quer %q", in, got, want)
+		}
+	}
+	if got := sdEscape(`\ " ]`); got != `\\ \" \]` {
+		t.Errorf("sdEscape = %q", got)
+	}
+}
+
+func TestSyslogFrame(t *testing.T) {
+	tests := []struct {
+		network string
+		stream  bool
+		want    string
+	}{
+		{"tcp", true, "8 olá\nfim"},
+		{"udp", false, "olá\nfim"},
+		{"", true, "olá\nfim\n"},
+		{"", false, "olá\nfim"},
+	}
+	for _, tt := range tests {
+		w := &syslogWriter{network: tt.network, stream: tt.stream}
+		if got := string(w.frame("olá\nfim")); got != tt.want {
+			t.Errorf("frame(%s, stream %v) = %q, quer %q", tt.network, tt.stream, got, tt.want)
+		}
+	}
+}
+
+func TestSyslogQueueDropsWhenFull(t *testing.T) {
+	// Sem a goroutine de envio a fila nunca esvazia
+	w := &syslogWriter{queue: make(chan string, syslogQueueSize), done: make(chan struct{})}
+
+	sent := make(chan struct{})
+	go func() {
+		for i := 0; i < syslogQueueSize+10; i++ {
+			w.send(fmt.Sprintf("mensagem %d", i))
+		}
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("send bloqueou com a fila cheia")
+	}
+	if n := w.dropped.Load(); n != 10 {
+		t.Errorf("%d mensagens descartadas, quer 10", n)
+	}
+
+	// Depois de fechada a fila, send apenas ignora a mensagem
+	w.mu.Lock()
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+	w.send("depois do close")
+}
+
+func TestSyslogWriterFlushesOnClose(t *testing.T) {
+	addr, next := listenSyslog(t)
+	network, address, err := ParseSyslogAddr(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newSyslogWriter(network, address)
+	w.send("primeira")
+	w.send("segunda")
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+	// As mensagens na fila são enviadas antes do close terminar
+	for _, want := range []string{"primeira", "segunda"} {
+		if got := next(); got != want {
+			t.Errorf("mensagem = %q, quer %q", got, want)
+		}
+	}
+}
+
+func TestParseSyslogAddr(t *testing.T) {
+	valid := map[string]string{
+		"":                      "",
+		"unix:///dev/log":       "unix",
+		"udp://10.0.0.1:514":    "udp",
+		"tcp://logs.local:6514": "tcp",
+	}
+	for addr, want := range valid {
+		if network, _, err := ParseSyslogAddr(addr); err != nil || network != want {
+			t.Errorf("ParseSyslogAddr(%q) = %q, %v; quer %q", addr, network, err, want)
+		}
+	}
+	for _, addr := range []string{"10.0.0.1:514", "udp://10.0.0.1", "http://logs:80", "tcp://"} {
+		if _, _, err := ParseSyslogAddr(addr); err == nil {
+			t.Errorf("ParseSyslogAddr(%q) aceito", addr)
+		}
+	}
+}
@@ -2,8 +2,8 @@ package system
 
 import (
 	"fmt"
-	"log"
 
+	"github.com/dev/falcon-agent/pkg/logger"
 	"github.com/jaypipes/ghw"
 )
 
@@ -22,16 +22,17 @@ func GetMemoryInfo() (*MemoryInfo, error) {
 	}
 
 	info := &MemoryInfo{}
+	log := logger.Default().With("component", "system")
 
 	// Log do número total de módulos encontrados
-	log.Printf("Número de módulos de memória encontrados: %d", len(memory.Modules))
+	log.Debug("Número de módulos de memória encontrados: %d", len(memory.Modules))
 
 	// Se houver módulos de memória, pegar informações do primeiro módulo
 	if len(memory.Modules) > 0 {
 		module := memory.Modules[0]
 
 		// Log das informações brutas do módulo
-		log.Printf("Informações do módulo: %+v", module)
+		log.Debug("Informações do módulo: %+v", module)
 
 		// Converter tamanho para GB
 		sizeGB := float64(module.SizeBytes) / (1024 * 1024 * 1024)
@@ -40,7 +41,7 @@ func GetMemoryInfo() (*MemoryInfo, error) {
 		info.Manufacturer = module.Vendor
 		info.SerialNumber = module.SerialNumber
 	} else {
-		log.Printf("Nenhum módulo de memória encontrado")
+		log.Warn("Nenhum módulo de memória encontrado")
 	}
 
 	return info, nil
@@ -3,6 +3,9 @@ package ui
 import (
 	"fmt"
 	"image/color"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -13,19 +16,34 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"fyne.io/systray"
+	"github.com/dev/falcon-agent/internal/diskhealth"
+	"github.com/dev/falcon-agent/internal/inventory"
 	"github.com/dev/falcon-agent/internal/model"
 	"github.com/dev/falcon-agent/internal/service"
+	"github.com/dev/falcon-agent/internal/usb"
 )
 
 type App struct {
 	window      fyne.Window
+	agent       *service.Agent
 	machineInfo *model.MachineInfo
-	updateChan  chan *model.MachineInfo
 	content     *fyne.Container
-	systemTray  fyne.App
+	currentPage func() *fyne.Container
+	// pageStop é fechado quando a página atual deixa de ser exibida, para
+	// encerrar atualizações em segundo plano da página
+	pageStop chan struct{}
+	// pageMu protege machineInfo, currentPage e pageStop, alterados pela
+	// interface, pelos eventos USB e pelas atualizações periódicas
+	pageMu     sync.Mutex
+	systemTray fyne.App
+
+	performanceWindow int
+	softwareQuery     string
+	processQuery      string
+	processSort       int
 }
 
-func New(machineInfo *model.MachineInfo) *App {
+func New(agent *service.Agent) *App {
 	a := app.New()
 	window := a.NewWindow("Falcon Agent")
 	window.Resize(fyne.NewSize(600, 400))
@@ -45,19 +63,19 @@ func New(machineInfo *model.MachineInfo) *App {
 		window.Hide()
 	})
 
-	updateChan := make(chan *model.MachineInfo, 1)
-	updateChan <- machineInfo
-
 	app := &App{
 		window:      window,
-		machineInfo: machineInfo,
-		updateChan:  updateChan,
+		agent:       agent,
+		machineInfo: agent.MachineInfo(),
 		systemTray:  a,
 	}
 
 	app.setupUI()
 	go app.setupSystemTray()
 	go app.updateLoop()
+	agent.OnUSBEvent(app.handleUSBEvent)
+	agent.OnInventoryChange(app.handleInventoryChange)
+	agent.OnDiskHealthEvent(app.handleDiskHealthEvent)
 
 	return app
 }
@@ -95,7 +113,9 @@ func (a *App) setupUI() {
 	sidebar := a.createSidebar()
 	separator := canvas.NewRectangle(theme.ShadowColor())
 	separator.SetMinSize(fyne.NewSize(2, 0))
-	a.content = a.createSystemContent()
+	a.currentPage = a.createSystemContent
+	a.pageStop = make(chan struct{})
+	a.content = container.NewMax(a.currentPage())
 	split := container.NewHSplit(
 		container.NewHBox(sidebar, separator),
 		container.NewPadded(a.content),
@@ -141,28 +161,40 @@ func (a *App) createSidebar() *fyne.Container {
 		action func()
 	}{
 		{theme.ComputerIcon(), "Sistema", func() {
-			a.content.Objects = []fyne.CanvasObject{a.createSystemContent()}
-			a.content.Refresh()
+			a.showPage(a.createSystemContent)
 		}},
 		{theme.MediaPlayIcon(), "CPU", func() {
-			a.content.Objects = []fyne.CanvasObject{a.createProcessorContent()}
-			a.content.Refresh()
+			a.showPage(a.createProcessorContent)
 		}},
 		{theme.StorageIcon(), "Memória", func() {
-			a.content.Objects = []fyne.CanvasObject{a.createMemoryContent()}
-			a.content.Refresh()
+			a.showPage(a.createMemoryContent)
 		}},
 		{theme.FolderIcon(), "Armazenamento", func() {
-			a.content.Objects = []fyne.CanvasObject{a.createStorageContent()}
-			a.content.Refresh()
+			a.showPage(a.createStorageContent)
+		}},
+		{theme.MediaFastForwardIcon(), "Desempenho", func() {
+			a.showPage(a.createPerformanceContent)
 		}},
 		{theme.SettingsIcon(), "BIOS", func() {
-			a.content.Objects = []fyne.CanvasObject{a.createBIOSContent()}
-			a.content.Refresh()
+			a.showPage(a.createBIOSContent)
 		}},
 		{theme.MediaRecordIcon(), "USB", func() {
-			a.content.Objects = []fyne.CanvasObject{a.createUSBContent()}
-			a.content.Refresh()
+			a.showPage(a.createUSBContent)
+		}},
+		{theme.UploadIcon(), "Rede", func() {
+			a.showPage(a.createNetworkContent)
+		}},
+		{theme.ViewFullScreenIcon(), "Vídeo", func() {
+			a.showPage(a.createDisplayContent)
+		}},
+		{theme.GridIcon(), "PCI", func() {
+			a.showPage(a.createPCIContent)
+		}},
+		{theme.ListIcon(), "Software", func() {
+			a.showPage(a.createSoftwareContent)
+		}},
+		{theme.ViewRefreshIcon(), "Processos", func() {
+			a.showPage(a.createProcessesContent)
 		}},
 	}
 
@@ -174,21 +206,22 @@ func (a *App) createSidebar() *fyne.Container {
 	return menuContainer
 }
 
-func (a *App) createSystemContent() *fyne.Container {
-	title := widget.NewLabelWithStyle(
-		"Informações do Sistema",
-		fyne.TextAlignCenter,
-		fyne.TextStyle{Bold: true},
-	)
-	grid := container.NewGridWithColumns(2,
-		createModernCard("Sistema Operacional", widget.NewLabel(a.machineInfo.OS)),
-		createModernCard("Hostname", widget.NewLabel(a.machineInfo.Hostname)),
-		createModernCard("Serial Number", widget.NewLabel(a.machineInfo.SerialNumber)),
-	)
-	return container.NewVBox(
-		container.NewPadded(title),
-		container.NewPadded(grid),
-	)
+// showPage troca o conteúdo exibido e lembra a página para as atualizações
+func (a *App) showPage(page func() *fyne.Container) {
+	// Chamada tanto pela interface quanto pelas atualizações em segundo plano
+	a.pageMu.Lock()
+	defer a.pageMu.Unlock()
+	a.renderPage(page)
+}
+
+// renderPage monta a página com o inventário em a.machineInfo. Deve ser
+// chamada com pageMu travado, que também protege machineInfo e currentPage.
+func (a *App) renderPage(page func() *fyne.Container) {
+	close(a.pageStop)
+	a.pageStop = make(chan struct{})
+	a.currentPage = page
+	a.content.Objects = []fyne.CanvasObject{page()}
+	a.content.Refresh()
 }
 
 func (a *App) createProcessorContent() *fyne.Container {
@@ -232,42 +265,56 @@ func (a *App) createMemoryContent() *fyne.Container {
 	)
 }
 
-func (a *App) createStorageContent() *fyne.Container {
+func (a *App) createBIOSContent() *fyne.Container {
 	title := widget.NewLabelWithStyle(
-		"Informações de Armazenamento",
+		"Informações da BIOS",
 		fyne.TextAlignCenter,
 		fyne.TextStyle{Bold: true},
 	)
-	content := container.NewVBox()
-	for _, hd := range a.machineInfo.HDs {
-		card := createModernCard(hd.Model, container.NewVBox(
-			widget.NewLabel(fmt.Sprintf("Capacidade: %d GB", hd.SizeGB)),
-			widget.NewLabel(fmt.Sprintf("Serial: %s", hd.Serial)),
-		))
-		content.Add(card)
-	}
-	scroll := container.NewVScroll(content)
-	scroll.SetMinSize(fyne.NewSize(600, 400))
+	grid := container.NewGridWithColumns(2,
+		createModernCard("Fabricante", widget.NewLabel(a.machineInfo.BIOS.Vendor)),
+		createModernCard("Versão", widget.NewLabel(a.machineInfo.BIOS.Version)),
+		createModernCard("Data de Lançamento", widget.NewLabel(a.machineInfo.BIOS.ReleaseDate)),
+	)
 	return container.NewVBox(
 		container.NewPadded(title),
-		container.NewPadded(scroll),
+		container.NewPadded(grid),
 	)
 }
 
-func (a *App) createBIOSContent() *fyne.Container {
+func (a *App) createDisplayContent() *fyne.Container {
 	title := widget.NewLabelWithStyle(
-		"Informações da BIOS",
+		"Placas de Vídeo e Monitores",
 		fyne.TextAlignCenter,
 		fyne.TextStyle{Bold: true},
 	)
-	grid := container.NewGridWithColumns(2,
-		createModernCard("Fabricante", widget.NewLabel(a.machineInfo.BIOS.Vendor)),
-		createModernCard("Versão", widget.NewLabel(a.machineInfo.BIOS.Version)),
-		createModernCard("Data de Lançamento", widget.NewLabel(a.machineInfo.BIOS.ReleaseDate)),
-	)
+	content := container.NewVBox()
+	for _, gpu := range a.machineInfo.GPUs {
+		vram := "Não informada"
+		if gpu.VRAMMB > 0 {
+			vram = fmt.Sprintf("%d MB", gpu.VRAMMB)
+		}
+		card := createModernCard(gpu.Model, container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("Fabricante: %s", gpu.Vendor)),
+			widget.NewLabel(fmt.Sprintf("Endereço PCI: %s", gpu.PCIAddress)),
+			widget.NewLabel(fmt.Sprintf("Driver: %s", orNone(gpu.Driver))),
+			widget.NewLabel(fmt.Sprintf("VRAM: %s", vram)),
+		))
+		content.Add(card)
+	}
+	for _, monitor := range a.machineInfo.Monitors {
+		card := createModernCard(fmt.Sprintf("%s %s", monitor.Manufacturer, monitor.Model), container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("Saída: %s", monitor.Connector)),
+			widget.NewLabel(fmt.Sprintf("Resolução: %dx%d", monitor.Width, monitor.Height)),
+			widget.NewLabel(fmt.Sprintf("Serial: %s", orNone(monitor.Serial))),
+		))
+		content.Add(card)
+	}
+	scroll := container.NewVScroll(content)
+	scroll.SetMinSize(fyne.NewSize(600, 400))
 	return container.NewVBox(
 		container.NewPadded(title),
-		container.NewPadded(grid),
+		container.NewPadded(scroll),
 	)
 }
 
@@ -294,17 +341,92 @@ func (a *App) createUSBContent() *fyne.Container {
 	)
 }
 
+// updateLoop recarrega periodicamente o inventário mantido pelo agente e
+// redesenha a página apenas quando algo exibido mudou
 func (a *App) updateLoop() {
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 	for {
 		select {
 		case <-ticker.C:
-			if newInfo, err := service.CollectMachineInfo(); err == nil {
-				a.updateChan <- newInfo
-				a.machineInfo = newInfo
-				a.content.Refresh()
+			newInfo := a.agent.MachineInfo()
+			a.pageMu.Lock()
+			// Além das mudanças de hardware, a tela mostra dados que
+			// mudam sem troca de peças (endereços de rede, monitores)
+			changed := newInfo != a.machineInfo && !reflect.DeepEqual(displayed(a.machineInfo), displayed(newInfo))
+			a.machineInfo = newInfo
+			if changed {
+				a.renderPage(a.currentPage)
 			}
+			a.pageMu.Unlock()
 		}
 	}
 }
+
+// displayed devolve uma cópia do inventário sem os valores que mudam a cada
+// coleta: o tempo ligado é ignorado e o uso das partições é arredondado
+// para a precisão exibida, para não redesenhar a página a cada minuto
+func displayed(info *model.MachineInfo) *model.MachineInfo {
+	if info == nil {
+		return nil
+	}
+	const unit = 1024 * 1024 * 1024 / 10 // 0,1 GB, como em formatGB
+	cp := *info
+	cp.OSDetails.UptimeSeconds = 0
+	cp.HDs = make([]model.HDInfo, len(info.HDs))
+	for i, hd := range info.HDs {
+		hd.Partitions = append([]model.PartitionInfo(nil), hd.Partitions...)
+		for j := range hd.Partitions {
+			hd.Partitions[j].UsedBytes /= unit
+			hd.Partitions[j].FreeBytes /= unit
+		}
+		cp.HDs[i] = hd
+	}
+	return &cp
+}
+
+// handleInventoryChange avisa o usuário sobre mudanças de hardware. As
+// mudanças de USB já são avisadas pelos eventos de conexão.
+func (a *App) handleInventoryChange(changes []inventory.Change) {
+	var summaries []string
+	for _, change := range changes {
+		if change.Component != inventory.ComponentUSB {
+			summaries = append(summaries, change.Summary)
+		}
+	}
+	if len(summaries) == 0 {
+		return
+	}
+	if len(summaries) > 3 {
+		summaries = append(summaries[:3], fmt.Sprintf("e mais %d mudanças", len(summaries)-3))
+	}
+	a.systemTray.SendNotification(fyne.NewNotification("Mudança de hardware detectada", strings.Join(summaries, "\n")))
+}
+
+// handleDiskHealthEvent avisa o usuário quando a saúde de um disco piora,
+// para que ele seja trocado antes de falhar
+func (a *App) handleDiskHealthEvent(events []diskhealth.Event) {
+	summaries := make([]string, 0, len(events))
+	for _, event := range events {
+		summaries = append(summaries, event.Summary)
+	}
+	a.systemTray.SendNotification(fyne.NewNotification("Saúde do disco piorou", strings.Join(summaries, "\n")))
+}
+
+// handleUSBEvent atualiza a tela e avisa o usuário quando um dispositivo
+// USB é conectado ou desconectado
+func (a *App) handleUSBEvent(event usb.Event) {
+	a.pageMu.Lock()
+	a.machineInfo = a.agent.MachineInfo()
+	a.renderPage(a.currentPage)
+	a.pageMu.Unlock()
+
+	title := "Dispositivo USB conectado"
+	switch {
+	case event.Type == usb.DeviceDetached:
+		title = "Dispositivo USB desconectado"
+	case event.Decision != nil && event.Decision.Action == usb.ActionBlock && event.Decision.Enforced:
+		title = "Dispositivo USB bloqueado"
+	}
+	a.systemTray.SendNotification(fyne.NewNotification(title, event.Device.Name))
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// interfaceTypeNames traduz o tipo da interface para exibição
+var interfaceTypeNames = map[string]string{
+	model.InterfaceWired:    "Cabeada",
+	model.InterfaceWireless: "Sem fio",
+	model.InterfaceVirtual:  "Virtual",
+}
+
+func (a *App) createNetworkContent() *fyne.Container {
+	title := widget.NewLabelWithStyle(
+		"Informações de Rede",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	network := a.machineInfo.Network
+	content := container.NewVBox(
+		createModernCard("Servidores DNS", widget.NewLabel(orNone(strings.Join(network.DNSServers, ", ")))),
+	)
+	for _, iface := range network.Interfaces {
+		state := "Desconectada"
+		if iface.Up {
+			state = "Conectada"
+		}
+		speed := "Desconhecida"
+		if iface.SpeedMbps > 0 {
+			speed = fmt.Sprintf("%d Mbps", iface.SpeedMbps)
+		}
+
+		card := createModernCard(fmt.Sprintf("%s (%s)", iface.Name, interfaceTypeNames[iface.Type]), container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("Estado: %s", state)),
+			widget.NewLabel(fmt.Sprintf("MAC: %s", orNone(iface.MAC))),
+			widget.NewLabel(fmt.Sprintf("IPv4: %s", orNone(strings.Join(iface.IPv4, ", ")))),
+			widget.NewLabel(fmt.Sprintf("IPv6: %s", orNone(strings.Join(iface.IPv6, ", ")))),
+			widget.NewLabel(fmt.Sprintf("Gateway: %s", orNone(strings.Join(iface.Gateways, ", ")))),
+			widget.NewLabel(fmt.Sprintf("Velocidade: %s", speed)),
+			widget.NewLabel(fmt.Sprintf("MTU: %d", iface.MTU)),
+			widget.NewLabel(fmt.Sprintf("Driver: %s", orNone(iface.Driver))),
+		))
+		content.Add(card)
+	}
+
+	scroll := container.NewVScroll(content)
+	scroll.SetMinSize(fyne.NewSize(600, 400))
+	return container.NewVBox(
+		container.NewPadded(title),
+		container.NewPadded(scroll),
+	)
+}
+
+// orNone mostra um traço no lugar de valores vazios
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// createPCIContent lista os dispositivos PCI agrupados pela classe, para
+// que placas de rede ou de captura inesperadas fiquem fáceis de achar
+func (a *App) createPCIContent() *fyne.Container {
+	title := widget.NewLabelWithStyle(
+		"Dispositivos PCI",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	devices := append([]model.PCIDevice(nil), a.machineInfo.PCIDevices...)
+	sort.SliceStable(devices, func(i, j int) bool {
+		return devices[i].ClassID < devices[j].ClassID
+	})
+
+	content := container.NewVBox()
+	for _, dev := range devices {
+		name := dev.Product
+		if name == "" {
+			name = fmt.Sprintf("Dispositivo %s:%s", dev.VendorID, dev.ProductID)
+		}
+		vendor := dev.Vendor
+		if vendor == "" {
+			vendor = dev.VendorID
+		}
+
+		card := createModernCard(name, container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("Classe: %s", orNone(dev.Class))),
+			widget.NewLabel(fmt.Sprintf("Fabricante: %s", vendor)),
+			widget.NewLabel(fmt.Sprintf("Endereço: %s", dev.Address)),
+			widget.NewLabel(fmt.Sprintf("Subsistema: %s", orNone(dev.Subsystem))),
+			widget.NewLabel(fmt.Sprintf("Driver: %s", orNone(dev.Driver))),
+		))
+		content.Add(card)
+	}
+
+	scroll := container.NewVScroll(content)
+	scroll.SetMinSize(fyne.NewSize(600, 400))
+	return container.NewVBox(
+		container.NewPadded(title),
+		container.NewPadded(scroll),
+	)
+}
@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"image"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/dev/falcon-agent/internal/charts"
+	"github.com/dev/falcon-agent/internal/metrics"
+)
+
+// performanceRefresh é o intervalo de atualização dos gráficos
+const performanceRefresh = 5 * time.Second
+
+// performanceWindow é um período selecionável na página de desempenho.
+// Sem resolução, os dados vêm do histórico em memória quando ele cobre o
+// período, ou dos pontos brutos em disco; com resolução, dos agregados em
+// disco.
+type performanceWindow struct {
+	label      string
+	duration   time.Duration
+	resolution metrics.Resolution
+}
+
+var performanceWindows = []performanceWindow{
+	{label: "Últimos 5 minutos", duration: 5 * time.Minute},
+	{label: "Última hora", duration: time.Hour, resolution: metrics.ResolutionMinute},
+	{label: "Últimas 24 horas", duration: 24 * time.Hour, resolution: metrics.ResolutionMinute},
+	{label: "Últimos 7 dias", duration: 7 * 24 * time.Hour, resolution: metrics.ResolutionHour},
+}
+
+func (a *App) createPerformanceContent() *fyne.Container {
+	title := widget.NewLabelWithStyle(
+		"Desempenho",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	cpuChart := newChartImage()
+	memoryChart := newChartImage()
+	diskChart := newChartImage()
+	networkChart := newChartImage()
+
+	// update roda na goroutine abaixo e na troca de período pela interface;
+	// mu protege o período escolhido e serializa a troca dos gráficos
+	var mu sync.Mutex
+	update := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		window := performanceWindows[a.performanceWindow]
+		cpu := a.performanceSeries("cpu_usage", window, 1)
+		memory := a.performanceSeries("memory_usage", window, 1)
+		diskRead := a.performanceSeries("disk_read_bytes", window, 1.0/1024)
+		diskWrite := a.performanceSeries("disk_write_bytes", window, 1.0/1024)
+		netRecv := a.performanceSeries("net_recv_bytes", window, 1.0/1024)
+		netSent := a.performanceSeries("net_sent_bytes", window, 1.0/1024)
+
+		setChart(cpuChart)(charts.CreateCPUChart(cpu))
+		setChart(memoryChart)(charts.CreateMemoryChart(memory))
+		setChart(diskChart)(charts.CreateDiskIOChart(diskRead, diskWrite))
+		setChart(networkChart)(charts.CreateNetworkChart(netRecv, netSent))
+	}
+
+	labels := make([]string, len(performanceWindows))
+	for i, w := range performanceWindows {
+		labels[i] = w.label
+	}
+	windowSelect := widget.NewSelect(labels, nil)
+	windowSelect.SetSelectedIndex(a.performanceWindow)
+	windowSelect.OnChanged = func(string) {
+		mu.Lock()
+		a.performanceWindow = windowSelect.SelectedIndex()
+		mu.Unlock()
+		update()
+	}
+
+	update()
+
+	// Atualiza os gráficos enquanto a página estiver visível
+	stop := a.pageStop
+	go func() {
+		ticker := time.NewTicker(performanceRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				update()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	grid := container.NewGridWithColumns(2,
+		createModernCard("CPU", cpuChart),
+		createModernCard("Memória", memoryChart),
+		createModernCard("Disco", diskChart),
+		createModernCard("Rede", networkChart),
+	)
+	return container.NewVBox(
+		container.NewPadded(title),
+		container.NewPadded(container.NewBorder(nil, nil, widget.NewLabel("Período:"), nil, windowSelect)),
+		container.NewPadded(grid),
+	)
+}
+
+// performanceSeries retorna os pontos da métrica no período, multiplicados
+// por scale (usado para converter bytes/s em KB/s)
+func (a *App) performanceSeries(name string, window performanceWindow, scale float64) []charts.TimeValue {
+	var data []charts.TimeValue
+
+	// O histórico em memória tem HistorySize pontos, que cobrem menos que
+	// o período quando metrics.interval é curto
+	inMemory := time.Duration(metrics.HistorySize) * a.agent.Config().MetricsInterval
+	if window.resolution == "" && inMemory >= window.duration {
+		history, ok := a.agent.Metrics().Histories()[name]
+		if !ok {
+			return nil
+		}
+		since := time.Now().Add(-window.duration)
+		for _, p := range history.GetPoints() {
+			if p.Timestamp.After(since) {
+				data = append(data, charts.TimeValue{Time: p.Timestamp, Value: p.Value * scale})
+			}
+		}
+		return data
+	}
+
+	store := a.agent.MetricStore()
+	if store == nil {
+		return nil
+	}
+	now := time.Now()
+	resolution := window.resolution
+	if resolution == "" {
+		resolution = metrics.ResolutionRaw
+	}
+	points, err := store.Query(name, now.Add(-window.duration), now, resolution)
+	if err != nil {
+		return nil
+	}
+	for _, p := range points {
+		data = append(data, charts.TimeValue{Time: p.Timestamp, Value: p.Avg * scale})
+	}
+	return data
+}
+
+func newChartImage() *canvas.Image {
+	img := canvas.NewImageFromImage(nil)
+	img.FillMode = canvas.ImageFillContain
+	img.SetMinSize(fyne.NewSize(400, 200))
+	return img
+}
+
+// setChart retorna uma função que troca a imagem do gráfico pelo
+// resultado de um charts.Create*; sem dados a imagem anterior é mantida
+func setChart(img *canvas.Image) func(image.Image, error) {
+	return func(chart image.Image, err error) {
+		if err != nil || chart == nil {
+			return
+		}
+		img.Image = chart
+		img.Refresh()
+	}
+}
@@ -0,0 +1,197 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/dev/falcon-agent/internal/metrics"
+)
+
+// processSort é uma ordenação selecionável na página de processos
+type processSort struct {
+	label string
+	by    string
+}
+
+var processSorts = []processSort{
+	{label: "CPU", by: metrics.SortByCPU},
+	{label: "Memória", by: metrics.SortByMemory},
+	{label: "Arquivos abertos", by: metrics.SortByFiles},
+	{label: "PID", by: metrics.SortByPID},
+	{label: "Nome", by: metrics.SortByName},
+}
+
+// peakProcesses é o número de processos mostrados no pico de CPU
+const peakProcesses = 3
+
+// createProcessesContent lista os processos em execução, com busca e
+// ordenação, e mostra quais processos mais usaram CPU no maior pico dos
+// últimos minutos. A lista é atualizada junto com os gráficos de desempenho.
+func (a *App) createProcessesContent() *fyne.Container {
+	title := widget.NewLabelWithStyle(
+		"Processos",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	if a.agent.Config().TopProcesses == 0 {
+		return container.NewVBox(
+			container.NewPadded(title),
+			container.NewPadded(widget.NewLabel("Leitura dos processos desativada (metrics.top_processes: 0)")),
+		)
+	}
+
+	// A lista é atualizada pela goroutine abaixo e lida pelos callbacks da
+	// interface; mu protege os processos, a busca e a ordenação
+	var (
+		mu            sync.Mutex
+		all, filtered []metrics.ProcessSample
+	)
+	count := widget.NewLabel("")
+	peak := widget.NewLabel("")
+	peak.Wrapping = fyne.TextWrapWord
+
+	list := widget.NewList(
+		func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(filtered)
+		},
+		func() fyne.CanvasObject {
+			// Linhas de comando longas são cortadas para caber na lista
+			command := widget.NewLabel("")
+			command.Truncation = fyne.TextTruncateEllipsis
+			return container.NewVBox(
+				widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+				command,
+			)
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			mu.Lock()
+			if id >= len(filtered) {
+				// A lista encolheu depois que a interface leu o tamanho
+				mu.Unlock()
+				return
+			}
+			p := filtered[id]
+			mu.Unlock()
+			labels := item.(*fyne.Container).Objects
+			labels[0].(*widget.Label).SetText(fmt.Sprintf("%s (%d) · CPU %.1f%% · %s · %d arquivos",
+				p.Name, p.PID, p.CPUPercent, formatMB(p.RSSBytes), p.OpenFiles))
+			labels[1].(*widget.Label).SetText(processDetails(p))
+		},
+	)
+
+	// apply refiltra os processos; o lock é solto antes de redesenhar a
+	// lista, que chama os callbacks acima
+	apply := func() {
+		mu.Lock()
+		filtered = metrics.FilterProcesses(all, a.processQuery)
+		metrics.SortProcesses(filtered, processSorts[a.processSort].by)
+		summary := fmt.Sprintf("%d de %d processos", len(filtered), len(all))
+		mu.Unlock()
+
+		count.SetText(summary)
+		list.Refresh()
+	}
+	update := func() {
+		processes := a.agent.Processes()
+		mu.Lock()
+		all = processes
+		mu.Unlock()
+		peak.SetText(peakSummary(a.agent.Metrics().TopProcesses))
+		apply()
+	}
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Buscar por PID, usuário, nome ou comando")
+	search.SetText(a.processQuery)
+	search.OnChanged = func(query string) {
+		mu.Lock()
+		a.processQuery = query
+		mu.Unlock()
+		apply()
+	}
+
+	labels := make([]string, len(processSorts))
+	for i, s := range processSorts {
+		labels[i] = s.label
+	}
+	sortSelect := widget.NewSelect(labels, nil)
+	sortSelect.SetSelectedIndex(a.processSort)
+	sortSelect.OnChanged = func(string) {
+		mu.Lock()
+		a.processSort = sortSelect.SelectedIndex()
+		mu.Unlock()
+		apply()
+	}
+
+	update()
+
+	// Atualiza a lista enquanto a página estiver visível
+	stop := a.pageStop
+	go func() {
+		ticker := time.NewTicker(performanceRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				update()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return container.NewVBox(
+		container.NewPadded(title),
+		container.NewPadded(createModernCard("Pico recente de CPU", peak)),
+		container.NewPadded(container.NewBorder(nil, nil, nil,
+			container.NewHBox(widget.NewLabel("Ordenar por:"), sortSelect), search)),
+		container.NewPadded(count),
+		container.NewPadded(container.NewGridWrap(fyne.NewSize(600, 400), list)),
+	)
+}
+
+// peakSummary descreve a amostra com maior uso de CPU do histórico e os
+// processos que mais contribuíram para ela
+func peakSummary(history *metrics.ProcessHistory) string {
+	top, ok := history.Peak()
+	if !ok {
+		return "Aguardando a primeira amostra"
+	}
+
+	text := fmt.Sprintf("%.1f%% às %s", top.CPUUsage, top.Timestamp.Local().Format("15:04:05"))
+	var names []string
+	for i, p := range top.Processes {
+		if i == peakProcesses || p.CPUPercent == 0 {
+			break
+		}
+		names = append(names, fmt.Sprintf("%s (%d) %.1f%%", p.Name, p.PID, p.CPUPercent))
+	}
+	if len(names) > 0 {
+		text += ": " + strings.Join(names, ", ")
+	}
+	return text
+}
+
+// processDetails mostra o usuário, o horário de início e a linha de
+// comando do processo em uma linha
+func processDetails(p metrics.ProcessSample) string {
+	details := []string{orNone(p.User), "iniciado em " + p.StartTime.Local().Format("02/01/2006 15:04")}
+	if p.Command != "" {
+		details = append(details, p.Command)
+	}
+	return strings.Join(details, " · ")
+}
+
+// formatMB formata bytes em MB com uma casa decimal
+func formatMB(b uint64) string {
+	return fmt.Sprintf("%.1f MB", float64(b)/(1024*1024))
+}
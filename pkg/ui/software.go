@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// createSoftwareContent lista os programas instalados, com uma busca por
+// nome, versão, fornecedor ou origem. A busca é guardada no App para
+// sobreviver às atualizações da página.
+func (a *App) createSoftwareContent() *fyne.Container {
+	title := widget.NewLabelWithStyle(
+		"Software Instalado",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	packages := a.machineInfo.Software
+	filtered := filterSoftware(packages, a.softwareQuery)
+	count := widget.NewLabel("")
+	updateCount := func() {
+		count.SetText(fmt.Sprintf("%d de %d programas", len(filtered), len(packages)))
+	}
+	updateCount()
+
+	list := widget.NewList(
+		func() int { return len(filtered) },
+		func() fyne.CanvasObject {
+			return container.NewVBox(
+				widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+				widget.NewLabel(""),
+			)
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			pkg := filtered[id]
+			labels := item.(*fyne.Container).Objects
+			labels[0].(*widget.Label).SetText(fmt.Sprintf("%s %s", pkg.Name, pkg.Version))
+			labels[1].(*widget.Label).SetText(softwareDetails(pkg))
+		},
+	)
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Buscar por nome, versão, fornecedor ou origem")
+	search.SetText(a.softwareQuery)
+	search.OnChanged = func(query string) {
+		a.softwareQuery = query
+		filtered = filterSoftware(packages, query)
+		updateCount()
+		list.Refresh()
+	}
+
+	return container.NewVBox(
+		container.NewPadded(title),
+		container.NewPadded(search),
+		container.NewPadded(count),
+		container.NewPadded(container.NewGridWrap(fyne.NewSize(600, 400), list)),
+	)
+}
+
+// filterSoftware retorna os pacotes que contêm o texto buscado, sem
+// diferenciar maiúsculas de minúsculas
+func filterSoftware(packages []model.SoftwarePackage, query string) []model.SoftwarePackage {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return packages
+	}
+	var filtered []model.SoftwarePackage
+	for _, pkg := range packages {
+		text := strings.ToLower(strings.Join([]string{pkg.Name, pkg.Version, pkg.Vendor, pkg.Source}, " "))
+		if strings.Contains(text, query) {
+			filtered = append(filtered, pkg)
+		}
+	}
+	return filtered
+}
+
+// softwareDetails mostra a origem, a arquitetura, o fornecedor e a data de
+// instalação do pacote em uma linha
+func softwareDetails(pkg model.SoftwarePackage) string {
+	details := []string{pkg.Source}
+	if pkg.Arch != "" {
+		details = append(details, pkg.Arch)
+	}
+	if pkg.Vendor != "" {
+		details = append(details, pkg.Vendor)
+	}
+	if !pkg.InstallDate.IsZero() {
+		details = append(details, "instalado em "+pkg.InstallDate.Local().Format("02/01/2006"))
+	}
+	return strings.Join(details, " · ")
+}
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+func (a *App) createStorageContent() *fyne.Container {
+	title := widget.NewLabelWithStyle(
+		"Informações de Armazenamento",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+	health := make(map[string]model.DiskHealth)
+	for _, h := range a.machineInfo.DiskHealth {
+		health[h.Serial] = h
+	}
+
+	content := container.NewVBox()
+	for _, hd := range a.machineInfo.HDs {
+		name := hd.Name
+		if hd.Model != "" {
+			name = fmt.Sprintf("%s (%s)", hd.Model, hd.Name)
+		}
+
+		details := container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("Capacidade: %d GB", hd.SizeGB)),
+			widget.NewLabel(fmt.Sprintf("Serial: %s", orNone(hd.Serial))),
+			widget.NewLabel(fmt.Sprintf("Tipo: %s", diskKind(hd))),
+		)
+		if h, ok := health[hd.Serial]; ok && hd.Serial != "" {
+			details.Add(widget.NewLabel(fmt.Sprintf("Saúde: %s", healthSummary(h))))
+		}
+		for _, part := range hd.Partitions {
+			details.Add(widget.NewLabelWithStyle(partitionTitle(part), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+			if part.MountPoint == "" {
+				details.Add(widget.NewLabel(fmt.Sprintf("%s, não montada", formatGB(part.SizeBytes))))
+				continue
+			}
+			usage := widget.NewProgressBar()
+			if part.UsedBytes+part.FreeBytes > 0 {
+				usage.SetValue(float64(part.UsedBytes) / float64(part.UsedBytes+part.FreeBytes))
+			}
+			details.Add(widget.NewLabel(fmt.Sprintf("Montada em %s: %s usados, %s livres",
+				part.MountPoint, formatGB(part.UsedBytes), formatGB(part.FreeBytes))))
+			details.Add(usage)
+		}
+		content.Add(createModernCard(name, details))
+	}
+	scroll := container.NewVScroll(content)
+	scroll.SetMinSize(fyne.NewSize(600, 400))
+	return container.NewVBox(
+		container.NewPadded(title),
+		container.NewPadded(scroll),
+	)
+}
+
+// diskKind descreve o tipo do disco a partir da controladora e das flags
+// (ex.: "NVMe, SSD" ou "SCSI, removível, HDD")
+func diskKind(hd model.HDInfo) string {
+	var kind []string
+	switch {
+	case hd.NVMe:
+		kind = append(kind, "NVMe")
+	case hd.Controller != "":
+		kind = append(kind, strings.ToUpper(hd.Controller))
+	}
+	if hd.Removable {
+		kind = append(kind, "removível")
+	}
+	if hd.Rotational {
+		kind = append(kind, "HDD")
+	} else {
+		kind = append(kind, "SSD")
+	}
+	return strings.Join(kind, ", ")
+}
+
+// healthSummary resume a saúde do disco em uma linha (ex.: "OK, 35 °C,
+// 12000 horas ligado, 2 setores realocados")
+func healthSummary(h model.DiskHealth) string {
+	parts := []string{"OK"}
+	if !h.Passed {
+		parts[0] = "FALHA"
+	}
+	if h.TemperatureC > 0 {
+		parts = append(parts, fmt.Sprintf("%d °C", h.TemperatureC))
+	}
+	parts = append(parts, fmt.Sprintf("%d horas ligado", h.PowerOnHours))
+	if h.PercentageUsed > 0 {
+		parts = append(parts, fmt.Sprintf("%d%% de desgaste", h.PercentageUsed))
+	}
+	if h.ReallocatedSectors > 0 {
+		parts = append(parts, fmt.Sprintf("%d setores realocados", h.ReallocatedSectors))
+	}
+	if h.PendingSectors > 0 {
+		parts = append(parts, fmt.Sprintf("%d setores pendentes", h.PendingSectors))
+	}
+	if h.MediaErrors > 0 {
+		parts = append(parts, fmt.Sprintf("%d erros de mídia", h.MediaErrors))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// partitionTitle mostra a partição com o sistema de arquivos e o rótulo
+func partitionTitle(part model.PartitionInfo) string {
+	title := part.Name
+	if part.Filesystem != "" {
+		title += " - " + part.Filesystem
+	}
+	if part.Label != "" {
+		title += fmt.Sprintf(" (%s)", part.Label)
+	}
+	return title
+}
+
+// formatGB formata bytes em GB com uma casa decimal
+func formatGB(b uint64) string {
+	return fmt.Sprintf("%.1f GB", float64(b)/(1024*1024*1024))
+}
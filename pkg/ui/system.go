@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/dev/falcon-agent/internal/model"
+)
+
+// secureBootNames traduz o estado do Secure Boot para exibição
+var secureBootNames = map[string]string{
+	model.SecureBootEnabled:     "Ativado",
+	model.SecureBootDisabled:    "Desativado",
+	model.SecureBootUnsupported: "Não suportado (BIOS legado)",
+	"":                          "Desconhecido",
+}
+
+func (a *App) createSystemContent() *fyne.Container {
+	title := widget.NewLabelWithStyle(
+		"Informações do Sistema",
+		fyne.TextAlignCenter,
+		fyne.TextStyle{Bold: true},
+	)
+
+	osInfo := a.machineInfo.OSDetails
+	osName := a.machineInfo.OS
+	if osInfo.Name != "" {
+		osName = osInfo.Name + " " + osInfo.Version
+	}
+	kernel := osInfo.Kernel
+	if osInfo.Arch != "" {
+		kernel = fmt.Sprintf("%s (%s)", osInfo.Kernel, osInfo.Arch)
+	}
+	bootTime := "-"
+	if !osInfo.BootTime.IsZero() {
+		bootTime = fmt.Sprintf("%s (%s)", osInfo.BootTime.Local().Format("02/01/2006 15:04"),
+			formatUptime(time.Duration(osInfo.UptimeSeconds)*time.Second))
+	}
+
+	grid := container.NewGridWithColumns(2,
+		createModernCard("Sistema Operacional", widget.NewLabel(osName)),
+		createModernCard("Kernel", widget.NewLabel(orNone(kernel))),
+		createModernCard("Hostname", widget.NewLabel(a.machineInfo.Hostname)),
+		createModernCard("Serial Number", widget.NewLabel(a.machineInfo.SerialNumber)),
+		createModernCard("Ligado desde", widget.NewLabel(bootTime)),
+		createModernCard("Fuso horário", widget.NewLabel(orNone(osInfo.Timezone))),
+		createModernCard("Idioma", widget.NewLabel(orNone(osInfo.Locale))),
+		createModernCard("Ambiente", widget.NewLabel(environmentName(osInfo))),
+		createModernCard("Secure Boot", widget.NewLabel(secureBootNames[osInfo.SecureBoot])),
+	)
+	exportButton := widget.NewButtonWithIcon("Exportar inventário", theme.DocumentSaveIcon(), func() {
+		filename, err := a.agent.ExportInventory()
+		if err != nil {
+			a.systemTray.SendNotification(fyne.NewNotification("Erro na exportação", err.Error()))
+			return
+		}
+		a.systemTray.SendNotification(fyne.NewNotification("Inventário exportado", filename))
+	})
+	return container.NewVBox(
+		container.NewPadded(title),
+		container.NewPadded(grid),
+		container.NewPadded(exportButton),
+	)
+}
+
+// environmentName descreve onde o sistema roda: máquina física, virtual
+// (com o hipervisor) ou contêiner
+func environmentName(info model.OSInfo) string {
+	switch {
+	case info.Container != "" && info.Virtualization != "":
+		return fmt.Sprintf("Contêiner %s em máquina virtual %s", info.Container, info.Virtualization)
+	case info.Container != "":
+		return "Contêiner " + info.Container
+	case info.Virtualization != "":
+		return "Máquina virtual " + info.Virtualization
+	}
+	return "Máquina física"
+}
+
+// formatUptime formata o tempo ligado em dias, horas e minutos
+func formatUptime(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dmin", days, hours, minutes)
+	}
+	return fmt.Sprintf("%dh %dmin", hours, minutes)
+}